@@ -8,6 +8,15 @@ type bmp struct {
 	fileHeader *fileHeader
 	dibHeader  *dibHeader
 	pixelArray [][]byte
+	// Buffs where unused part of DIB header or ICC profile will be recorded (all metadata which differs from BITMAPINFOHEADER)
+	// see (https://en.wikipedia.org/wiki/BMP_file_format#DIB_header_(bitmap_information_header))
+	unusedBuf1 []byte
+	unusedBuf2 []byte
+	// Color pallete of the saved file, pixel array is always kept in 24 bits
+	outputBitsPerPixel uint16
+	colorTable         colorTable
+	// Alpha channel of 32 bit image in the order of pixel array, nil for opaque images
+	alpha [][]byte
 }
 
 // Device independent bitmap header
@@ -58,8 +67,17 @@ type dibHeader struct {
 // 	BI_CMYKRLE4 = 13
 // )
 
-// Color table for color pallete <= 8 bits (will be added in future)
-// type colorTable []byte
+// Color table for color pallete <= 8 bits
+// see (https://en.wikipedia.org/wiki/BMP_file_format#Color_table)
+type colorTable []rgbQuad
+
+// Entry of color table, colors are stored in BGR order
+type rgbQuad struct {
+	Blue     byte
+	Green    byte
+	Red      byte
+	Reserved byte
+}
 
 func (b *bmp) PrintHeader() {
 	fmt.Println("BMP Header:")
@@ -77,3 +95,29 @@ func (b *bmp) PrintHeader() {
 func (b *bmp) GetPixelNumber() uint16 {
 	return b.dibHeader.BitsPerPixel
 }
+
+// rowSize returns the number of bytes in pixel row, rows are padded to 4 bytes
+func (b *bmp) rowSize() uint32 {
+	return (uint32(b.dibHeader.BitsPerPixel)*b.dibHeader.Width + 31) / 32 * 4
+}
+
+// row returns the pixel row by its index counting from the top of the image,
+// rows are stored bottom-up in the pixel array
+func (b *bmp) row(y int) []byte {
+	return b.pixelArray[len(b.pixelArray)-1-y]
+}
+
+// setSize changes dimensions of the image, recalculates row padding and sizes in headers,
+// pixel array is replaced with the blank one, so the caller keeps the old one if needed
+func (b *bmp) setSize(width, height uint32) {
+	b.dibHeader.Width = width
+	b.dibHeader.Height = height
+	rowSize := b.rowSize()
+	b.dibHeader.ImageSize = rowSize * height
+	b.fileHeader.FileSize = b.fileHeader.Offset + b.dibHeader.ImageSize + uint32(len(b.unusedBuf2))
+
+	b.pixelArray = make([][]byte, height)
+	for idx := range b.pixelArray {
+		b.pixelArray[idx] = make([]byte, rowSize)
+	}
+}
@@ -3,6 +3,7 @@ package bmp
 import (
 	"encoding/binary"
 	"errors"
+	"math/bits"
 	"os"
 )
 
@@ -12,6 +13,7 @@ var (
 	ErrFileIsCorrupted           = errors.New("BMP File is corrupted")
 	ErrIncorrectFileFormat       = errors.New("File's format does not match BMP format")
 	ErrNon24BitImageNotSupported = errors.New("Image with 24 bit color pallete is not supported")
+	ErrUnsupportedCompression    = errors.New("BMP compression method is not supported")
 )
 
 // File header of Device independent bitmap
@@ -24,19 +26,13 @@ type fileHeader struct {
 	Offset    uint32
 }
 
-// Variables
-var (
-	// Buffs where unused part of DIB header or ICC profile will be recorded (all metadata which differs from BITMAPINFOHEADER)
-	// see (https://en.wikipedia.org/wiki/BMP_file_format#DIB_header_(bitmap_information_header))
-	unusedBuf1 []byte
-	unusedBuf2 []byte
-	// Needed to iterate over rows
-	rowSize uint32
-)
-
 // Constants
 const (
 	BMPsignature = 19778
+	// Compression methods of 32 bit images
+	biRGB            = 0
+	biBitfields      = 3
+	biAlphaBitfields = 6
 )
 
 // BMP file reading
@@ -69,11 +65,20 @@ func Load(fileName string) (*bmp, error) {
 	}
 
 	// Reading unused bytes until pixel array
-	unusedBuf1 = make([]byte, bmp.fileHeader.Offset-54)
-	if err := binary.Read(file, binary.LittleEndian, &unusedBuf1); err != nil {
+	bmp.unusedBuf1 = make([]byte, bmp.fileHeader.Offset-54)
+	if err := binary.Read(file, binary.LittleEndian, &bmp.unusedBuf1); err != nil {
 		return nil, err
 	}
 
+	// 32 bit image is decoded to 24 bit pixel array and alpha channel, headers are kept as they are in file,
+	// so the image is used only as a source of pixels, e.g. overlay
+	if bmp.dibHeader.BitsPerPixel == 32 {
+		if err := bmp.load32(file); err != nil {
+			return nil, err
+		}
+		return bmp, nil
+	}
+
 	// If not 24 bit, just skip the pixel array
 	// it's done to make possible reading header of images which color pallete is not 24
 	if bmp.dibHeader.BitsPerPixel != 24 {
@@ -83,7 +88,7 @@ func Load(fileName string) (*bmp, error) {
 	// Reading  pixel array
 
 	// row size is the number of pixels in one row by row
-	rowSize = (uint32(bmp.dibHeader.BitsPerPixel)*(bmp.dibHeader.Width) + 31) / 32 * 4
+	rowSize := bmp.rowSize()
 	pixelsNumber := rowSize * bmp.dibHeader.Height
 
 	bmp.pixelArray = make([][]byte, bmp.dibHeader.Height)
@@ -98,14 +103,73 @@ func Load(fileName string) (*bmp, error) {
 	}
 
 	// Reading unused bytes after pixel array
-	unusedBuf2 = make([]byte, bmp.fileHeader.FileSize-pixelsNumber-uint32(len(unusedBuf1))-54)
-	if err := binary.Read(file, binary.LittleEndian, &unusedBuf2); err != nil {
+	bmp.unusedBuf2 = make([]byte, bmp.fileHeader.FileSize-pixelsNumber-uint32(len(bmp.unusedBuf1))-54)
+	if err := binary.Read(file, binary.LittleEndian, &bmp.unusedBuf2); err != nil {
 		return nil, err
 	}
 
 	return bmp, nil
 }
 
+// load32 reads pixel array of 32 bit image, channels are masked by bit fields or go in BGRA order,
+// alpha channel of zeros is the unused byte of BI_RGB, so such image is opaque as well as the image without alpha mask
+func (b *bmp) load32(file *os.File) error {
+	masks := [4]uint32{0xff0000, 0xff00, 0xff, 0xff000000}
+	switch b.dibHeader.CompressionMethod {
+	case biRGB:
+	case biBitfields, biAlphaBitfields:
+		// Masks follow BITMAPINFOHEADER, alpha mask is present in V3 and later headers
+		if len(b.unusedBuf1) < 12 {
+			return ErrFileIsCorrupted
+		}
+		masks[3] = 0
+		for idx := 0; idx < 4 && idx*4+4 <= len(b.unusedBuf1); idx++ {
+			if idx == 3 && b.dibHeader.Size < 56 && b.dibHeader.CompressionMethod != biAlphaBitfields {
+				break
+			}
+			masks[idx] = binary.LittleEndian.Uint32(b.unusedBuf1[idx*4:])
+		}
+	default:
+		return ErrUnsupportedCompression
+	}
+
+	// channel extracts the masked value and scales it to 8 bits
+	channel := func(pixel, mask uint32) byte {
+		if mask == 0 {
+			return 255
+		}
+		maxValue := uint32(1)<<bits.OnesCount32(mask) - 1
+		return byte((pixel & mask >> bits.TrailingZeros32(mask)) * 255 / maxValue)
+	}
+
+	width := int(b.dibHeader.Width)
+	row := make([]byte, width*4)
+	b.pixelArray = make([][]byte, b.dibHeader.Height)
+	b.alpha = make([][]byte, b.dibHeader.Height)
+	unused, opaque := true, true
+	for rowIdx := range b.pixelArray {
+		if err := binary.Read(file, binary.LittleEndian, &row); err != nil {
+			return err
+		}
+		b.pixelArray[rowIdx] = make([]byte, (width*3+3)/4*4)
+		b.alpha[rowIdx] = make([]byte, width)
+		for colIdx := 0; colIdx < width; colIdx++ {
+			pixel := binary.LittleEndian.Uint32(row[colIdx*4:])
+			b.pixelArray[rowIdx][colIdx*3] = channel(pixel, masks[2])   // Blue
+			b.pixelArray[rowIdx][colIdx*3+1] = channel(pixel, masks[1]) // Green
+			b.pixelArray[rowIdx][colIdx*3+2] = channel(pixel, masks[0]) // Red
+			alpha := channel(pixel, masks[3])
+			b.alpha[rowIdx][colIdx] = alpha
+			unused, opaque = unused && alpha == 0, opaque && alpha == 255
+		}
+	}
+
+	if unused || opaque {
+		b.alpha = nil
+	}
+	return nil
+}
+
 func (b *bmp) Save(fileName string) error {
 	file, err := os.Create(fileName)
 	if err != nil {
@@ -113,6 +177,11 @@ func (b *bmp) Save(fileName string) error {
 	}
 	defer file.Close()
 
+	// Color pallete was reduced, image is written with BITMAPINFOHEADER only
+	if b.outputBitsPerPixel != 0 && b.outputBitsPerPixel != 24 {
+		return b.saveReduced(file)
+	}
+
 	// Writing file header
 	if err := binary.Write(file, binary.LittleEndian, b.fileHeader); err != nil {
 		return err
@@ -124,7 +193,7 @@ func (b *bmp) Save(fileName string) error {
 	}
 
 	// Writing unused bytes before pixel array
-	if err := binary.Write(file, binary.LittleEndian, unusedBuf1); err != nil {
+	if err := binary.Write(file, binary.LittleEndian, b.unusedBuf1); err != nil {
 		return err
 	}
 
@@ -136,9 +205,85 @@ func (b *bmp) Save(fileName string) error {
 	}
 
 	// Writing unsused bytes after pixel array
-	if err := binary.Write(file, binary.LittleEndian, unusedBuf2); err != nil {
+	if err := binary.Write(file, binary.LittleEndian, b.unusedBuf2); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// saveReduced writes the image with color pallete of outputBitsPerPixel,
+// pixels are looked up in the color table for pallete <= 8 bits
+// or packed to 5 bits per channel for 16 bits
+func (b *bmp) saveReduced(file *os.File) error {
+	bitsPerPixel := uint32(b.outputBitsPerPixel)
+	outputRowSize := (bitsPerPixel*b.dibHeader.Width + 31) / 32 * 4
+	headerSize := uint32(14 + 40 + 4*len(b.colorTable))
+
+	fileHeader := *b.fileHeader
+	fileHeader.Offset = headerSize
+	fileHeader.FileSize = headerSize + outputRowSize*b.dibHeader.Height
+
+	dibHeader := *b.dibHeader
+	dibHeader.Size = 40
+	dibHeader.BitsPerPixel = b.outputBitsPerPixel
+	dibHeader.CompressionMethod = 0
+	dibHeader.ImageSize = outputRowSize * b.dibHeader.Height
+	dibHeader.ColorsNumber = uint32(len(b.colorTable))
+	dibHeader.ImportantColorsNumber = 0
+
+	// Writing file header
+	if err := binary.Write(file, binary.LittleEndian, &fileHeader); err != nil {
+		return err
+	}
+
+	// Writing DIB header
+	if err := binary.Write(file, binary.LittleEndian, &dibHeader); err != nil {
+		return err
+	}
+
+	// Writing color table
+	if err := binary.Write(file, binary.LittleEndian, b.colorTable); err != nil {
+		return err
+	}
+
+	// Already found color table indices
+	indices := make(map[[3]byte]byte)
+	outputRow := make([]byte, outputRowSize)
+
+	// Writing pixel array row by row
+	for _, row := range b.pixelArray {
+		for idx := range outputRow {
+			outputRow[idx] = 0
+		}
+
+		for colIdx := uint32(0); colIdx < b.dibHeader.Width; colIdx++ {
+			blue, green, red := row[colIdx*3], row[colIdx*3+1], row[colIdx*3+2]
+
+			if bitsPerPixel == 16 {
+				// RGB 5-5-5, the highest bit is unused
+				value := uint16(red>>3)<<10 | uint16(green>>3)<<5 | uint16(blue>>3)
+				outputRow[colIdx*2] = byte(value)
+				outputRow[colIdx*2+1] = byte(value >> 8)
+				continue
+			}
+
+			index, ok := indices[[3]byte{blue, green, red}]
+			if !ok {
+				index = b.colorTable.nearest(blue, green, red)
+				indices[[3]byte{blue, green, red}] = index
+			}
+
+			// Pixels are packed starting from the highest bits of byte
+			pixelsInByte := 8 / bitsPerPixel
+			shift := 8 - bitsPerPixel*(colIdx%pixelsInByte+1)
+			outputRow[colIdx/pixelsInByte] |= index << shift
+		}
+
+		if err := binary.Write(file, binary.LittleEndian, outputRow); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
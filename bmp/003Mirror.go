@@ -8,35 +8,45 @@ var (
 )
 
 // Mirrors the image
+// values: h mirrors rows, v mirrors columns, transpose flips along the main diagonal from the top left corner,
+// transverse flips along the anti-diagonal from the top right corner, diagonal flips swap width and height
 func (b *bmp) Mirror(flagValue string) error {
-	newPixelArray := make([][]byte, 0, len(b.pixelArray))
-
 	switch flagValue {
 	case "h":
 		// Mirror rows along the horizontal line
-		for idx := len(b.pixelArray) - 1; idx >= 0; idx-- {
-			newPixelArray = append(newPixelArray, b.pixelArray[idx])
+		for top, bottom := 0, len(b.pixelArray)-1; top < bottom; top, bottom = top+1, bottom-1 {
+			b.pixelArray[top], b.pixelArray[bottom] = b.pixelArray[bottom], b.pixelArray[top]
 		}
 	case "v":
-		// Initialize new pixel array
-		rowSize := (uint32(b.dibHeader.BitsPerPixel)*(b.dibHeader.Width) + 31) / 32 * 4
-		for idx := 0; idx < len(b.pixelArray); idx++ {
-			newPixelArray = append(newPixelArray, make([]byte, rowSize))
+		// Mirror pixels along the vertical line in place, padding bytes stay at the end of rows
+		width := int(b.dibHeader.Width)
+		for _, row := range b.pixelArray {
+			for left, right := 0, width-1; left < right; left, right = left+1, right-1 {
+				for channel := 0; channel < 3; channel++ {
+					row[left*3+channel], row[right*3+channel] = row[right*3+channel], row[left*3+channel]
+				}
+			}
 		}
+	case "transpose", "transverse":
+		source := b.pixelArray
+		width, height := int(b.dibHeader.Width), len(source)
+		b.setSize(uint32(height), uint32(width))
 
-		// Mirror pixels along the vertical line
-		for colIdx := 0; uint32(colIdx) < rowSize; colIdx += 3 {
-			for rowIdx := 0; rowIdx < len(b.pixelArray); rowIdx++ {
-
-				newPixelArray[rowIdx][colIdx] = b.pixelArray[rowIdx][rowSize-uint32(colIdx)-3]   // blue
-				newPixelArray[rowIdx][colIdx+1] = b.pixelArray[rowIdx][rowSize-uint32(colIdx)-2] // green
-				newPixelArray[rowIdx][colIdx+2] = b.pixelArray[rowIdx][rowSize-uint32(colIdx)-1] // red
+		for y := 0; y < width; y++ {
+			row := b.row(y)
+			for x := 0; x < height; x++ {
+				// Source pixel in top-down coordinates
+				sourceX, sourceY := y, x
+				if flagValue == "transverse" {
+					sourceX, sourceY = width-1-y, height-1-x
+				}
+				sourceRow := source[height-1-sourceY]
+				copy(row[x*3:x*3+3], sourceRow[sourceX*3:sourceX*3+3])
 			}
 		}
 	default:
 		return ErrIncorrectMirrorValue
 	}
 
-	b.pixelArray = newPixelArray
 	return nil
 }
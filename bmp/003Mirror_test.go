@@ -0,0 +1,41 @@
+package bmp
+
+import "testing"
+
+func TestMirror(t *testing.T) {
+	// Image 3x2 where blue value of pixel is its number in top-down order:
+	// 1 2 3
+	// 4 5 6
+	tests := []struct {
+		value string
+		want  [][]byte
+	}{
+		{"h", [][]byte{{4, 5, 6}, {1, 2, 3}}},
+		{"v", [][]byte{{3, 2, 1}, {6, 5, 4}}},
+		{"transpose", [][]byte{{1, 4}, {2, 5}, {3, 6}}},
+		{"transverse", [][]byte{{6, 3}, {5, 2}, {4, 1}}},
+	}
+
+	for _, test := range tests {
+		testBmp := newTestBmp(3, 2, [3]byte{})
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 3; x++ {
+				testBmp.row(y)[x*3] = byte(y*3 + x + 1)
+			}
+		}
+
+		if err := testBmp.Mirror(test.value); err != nil {
+			t.Fatalf("Mirror(%s) error = %v", test.value, err)
+		}
+		if int(testBmp.dibHeader.Height) != len(test.want) || int(testBmp.dibHeader.Width) != len(test.want[0]) {
+			t.Fatalf("Mirror(%s) size = %dx%d", test.value, testBmp.dibHeader.Width, testBmp.dibHeader.Height)
+		}
+		for y, row := range test.want {
+			for x, want := range row {
+				if blue := testBmp.row(y)[x*3]; blue != want {
+					t.Errorf("Mirror(%s) pixel %d,%d = %d, want %d", test.value, x, y, blue, want)
+				}
+			}
+		}
+	}
+}
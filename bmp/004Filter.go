@@ -2,6 +2,8 @@ package bmp
 
 import (
 	"errors"
+
+	"bitmap/utils"
 )
 
 // Errors
@@ -11,90 +13,71 @@ var (
 )
 
 const (
-	eps = 0.01
+	// Default parameters of posterize and solarize
+	posterizeLevels   = 4
+	solarizeThreshold = 128
 )
 
 func (b *bmp) Filter(flagValue string) error {
-	switch flagValue {
-	case "red":
-		for rowIdx := range b.pixelArray {
-			// Nullify blue and green colors
-			for colIdx := uint32(0); colIdx < rowSize; colIdx += 3 {
-				b.pixelArray[rowIdx][colIdx] = 0   // Blue
-				b.pixelArray[rowIdx][colIdx+1] = 0 // Green
-			}
+	// Filter name and its parameters separated by colon: <name>:<parameter>
+	params := utils.Split(flagValue, ":")
+	rowSize := b.rowSize()
+
+	switch params[0] {
+	case "red", "green", "blue", "sepia", "negative":
+		// Channel filters, sepia and negative are presets of color matrix
+		b.applyColorMatrix(colorMatrixPresets[params[0]])
+	case "grayscale":
+		// Rec. 601 luma is used by default, other methods are passed after colon
+		method := "rec601"
+		if len(params) > 1 {
+			method = params[1]
 		}
-	case "green":
-		for rowIdx := range b.pixelArray {
-			// Nullify red and blue colors
-			for colIdx := uint32(0); colIdx < rowSize; colIdx += 3 {
-				b.pixelArray[rowIdx][colIdx] = 0   // Blue
-				b.pixelArray[rowIdx][colIdx+2] = 0 // Red
+		return b.Grayscale(method)
+	case "threshold":
+		return b.Threshold(params[1:])
+	case "dither":
+		return b.Dither(params[1:])
+	case "median", "mode", "bilateral", "nlm":
+		return b.Denoise(params[0], params[1:])
+	case "posterize":
+		// Number of levels per channel
+		levels := posterizeLevels
+		if len(params) > 1 {
+			value, ok := utils.Atoi(params[1])
+			if !ok || value < 2 || value > 256 {
+				return ErrIncorrectFilterValue
 			}
+			levels = value
 		}
-	case "blue":
-		for rowIdx := range b.pixelArray {
-			// Nullify red and green colors
-			for colIdx := uint32(0); colIdx < rowSize; colIdx += 3 {
-				b.pixelArray[rowIdx][colIdx+1] = 0 // Green
-				b.pixelArray[rowIdx][colIdx+2] = 0 // Red
-			}
+
+		// Every value is rounded to the closest of levels evenly distributed over [0, 255]
+		var lookup [256]byte
+		for value := range lookup {
+			level := (value*(levels-1) + 127) / 255
+			lookup[value] = byte(level * 255 / (levels - 1))
 		}
-	case "grayscale":
-		// Weighted method
-		// see (https://idmnyu.github.io/p5.js-image/Filters/index.html)
 		for rowIdx := range b.pixelArray {
-			for colIdx := uint32(0); colIdx+2 < rowSize; colIdx += 3 {
-				// Scale color value according to weight
-				RedVal := float32(b.pixelArray[rowIdx][colIdx]) * 0.11
-				GreenVal := float32(b.pixelArray[rowIdx][colIdx+1]) * 0.59
-				BlueVal := float32(b.pixelArray[rowIdx][colIdx+2]) * 0.3
-				// Sum colors to get gray color
-				LumaVal := byte(RedVal + GreenVal + BlueVal)
-				// Assign color to pixel
-				b.pixelArray[rowIdx][colIdx] = LumaVal   // Blue
-				b.pixelArray[rowIdx][colIdx+1] = LumaVal // Green
-				b.pixelArray[rowIdx][colIdx+2] = LumaVal // Red
+			for colIdx := uint32(0); colIdx < b.dibHeader.Width*3; colIdx++ {
+				b.pixelArray[rowIdx][colIdx] = lookup[b.pixelArray[rowIdx][colIdx]]
 			}
 		}
-	case "negative":
-		for rowIdx := range b.pixelArray {
-			for colIdx := uint32(0); colIdx+2 < rowSize; colIdx += 3 {
-				// Invert color values
-				b.pixelArray[rowIdx][colIdx] = 255 - b.pixelArray[rowIdx][colIdx]
-				b.pixelArray[rowIdx][colIdx+1] = 255 - b.pixelArray[rowIdx][colIdx+1]
-				b.pixelArray[rowIdx][colIdx+2] = 255 - b.pixelArray[rowIdx][colIdx+2]
+	case "solarize":
+		// Color values above threshold are inverted
+		threshold := solarizeThreshold
+		if len(params) > 1 {
+			value, ok := utils.Atoi(params[1])
+			if !ok || value < 0 || value > 255 {
+				return ErrIncorrectFilterValue
 			}
+			threshold = value
 		}
-	case "sepia":
-		// Microsoft recommended values
-		// see (https://idmnyu.github.io/p5.js-image/Filters/index.html)
+
 		for rowIdx := range b.pixelArray {
-			for colIdx := uint32(0); colIdx+2 < rowSize; colIdx += 3 {
-				// Get color values
-				blueColor, greenColor, redColor := b.pixelArray[rowIdx][colIdx], b.pixelArray[rowIdx][colIdx+1], b.pixelArray[rowIdx][colIdx+2]
-
-				// Apply ratio to colors
-				blueSepia := float32(redColor)*.272 + float32(greenColor)*.534 + float32(blueColor)*.131
-				// condition of exceeding 255
-				if blueSepia-255. > eps {
-					blueSepia = 255.
-				}
-				greenSepia := float32(redColor)*.349 + float32(greenColor)*.686 + float32(blueColor)*.168
-				// condition of exceeding 255
-				if greenSepia-255 > eps {
-					greenSepia = 255.
+			for colIdx := uint32(0); colIdx < b.dibHeader.Width*3; colIdx++ {
+				if int(b.pixelArray[rowIdx][colIdx]) > threshold {
+					b.pixelArray[rowIdx][colIdx] = 255 - b.pixelArray[rowIdx][colIdx]
 				}
-				redSepia := float32(redColor)*.393 + float32(greenColor)*.769 + float32(blueColor)*.189
-				// condition of exceeding 255
-				if redSepia-255 > eps {
-					redSepia = 255.
-				}
-
-				// Assign new colors
-				b.pixelArray[rowIdx][colIdx] = byte(blueSepia)
-				b.pixelArray[rowIdx][colIdx+1] = byte(greenSepia)
-				b.pixelArray[rowIdx][colIdx+2] = byte(redSepia)
 			}
 		}
 	case "pixelate":
@@ -196,9 +179,26 @@ func (b *bmp) boxPixelsSum(kernelSize, rowIdx, colIdx int) (sum_B, sum_G, sum_R
 
 // fetchPixel returns the slice of pixel's values
 func (b *bmp) fetchPixel(rowIdx, colIdx int) ([]byte, error) {
-	if rowIdx < 0 || rowIdx >= len(b.pixelArray) || colIdx < 0 || colIdx+3 >= int(rowSize) {
+	if rowIdx < 0 || rowIdx >= len(b.pixelArray) || colIdx < 0 || colIdx+3 >= int(b.rowSize()) {
 		return nil, ErrIndexOutOfBound
 	}
 
 	return b.pixelArray[rowIdx][colIdx : colIdx+3], nil
 }
+
+// window returns the bounds of the neighborhood with radius around the pixel clipped by the image borders,
+// rows are in range [top, bottom) and pixel columns are in range [left, right)
+func (b *bmp) window(rowIdx, colIdx, radius int) (top, bottom, left, right int) {
+	top, bottom = max(rowIdx-radius, 0), min(rowIdx+radius+1, len(b.pixelArray))
+	left, right = max(colIdx-radius, 0), min(colIdx+radius+1, int(b.dibHeader.Width))
+	return
+}
+
+// copyPixelArray returns the deep copy of pixel array, used as the source of neighborhood filters
+func (b *bmp) copyPixelArray() [][]byte {
+	pixelArray := make([][]byte, len(b.pixelArray))
+	for rowIdx, row := range b.pixelArray {
+		pixelArray[rowIdx] = append([]byte{}, row...)
+	}
+	return pixelArray
+}
@@ -0,0 +1,42 @@
+package bmp
+
+import "testing"
+
+func TestPosterizeSolarize(t *testing.T) {
+	type testData struct {
+		name  string
+		value string
+		pixel [3]byte
+		want  [3]byte
+		err   error
+	}
+
+	tests := []testData{
+		{name: "Posterize to 2 levels", value: "posterize:2", pixel: [3]byte{100, 200, 127}, want: [3]byte{0, 255, 0}},
+		{name: "Posterize to 4 levels by default", value: "posterize", pixel: [3]byte{100, 200, 30}, want: [3]byte{85, 170, 0}},
+		{name: "Posterize to 256 levels keeps values", value: "posterize:256", pixel: [3]byte{100, 200, 1}, want: [3]byte{100, 200, 1}},
+		{name: "Posterize to 1 level", value: "posterize:1", err: ErrIncorrectFilterValue},
+		{name: "Solarize at 128 by default", value: "solarize", pixel: [3]byte{100, 200, 128}, want: [3]byte{100, 55, 128}},
+		{name: "Solarize at 50", value: "solarize:50", pixel: [3]byte{50, 51, 255}, want: [3]byte{50, 204, 0}},
+		{name: "Solarize at 300", value: "solarize:300", err: ErrIncorrectFilterValue},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testBmp := newTestBmp(3, 2, test.pixel)
+			if err := testBmp.Filter(test.value); err != test.err {
+				t.Fatalf("Filter() error = %v, want %v", err, test.err)
+			}
+			if test.err != nil {
+				return
+			}
+			for _, row := range testBmp.pixelArray {
+				for colIdx := 0; colIdx < 3; colIdx++ {
+					if [3]byte(row[colIdx*3:colIdx*3+3]) != test.want {
+						t.Fatalf("Filter() pixel = %v, want %v", row[colIdx*3:colIdx*3+3], test.want)
+					}
+				}
+			}
+		})
+	}
+}
@@ -1 +1,13 @@
 package bmp
+
+// crop keeps the rectangle of image with top left corner at x, y
+// the rectangle must be inside of the image
+func (b *bmp) crop(x, y, width, height int) {
+	source := b.pixelArray
+	b.setSize(uint32(width), uint32(height))
+
+	for rowIdx := 0; rowIdx < height; rowIdx++ {
+		sourceRow := source[len(source)-1-(y+rowIdx)]
+		copy(b.row(rowIdx), sourceRow[x*3:(x+width)*3])
+	}
+}
@@ -0,0 +1,125 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+)
+
+// Errors
+var (
+	ErrIncorrectGrayscaleMethod = errors.New("Incorrect grayscale method provided")
+)
+
+// Lookup table of sRGB gamma decoded values
+// see (https://en.wikipedia.org/wiki/SRGB#Transformation)
+var srgbToLinear [256]float64
+
+func init() {
+	for value := range srgbToLinear {
+		channel := float64(value) / 255.
+		if channel <= 0.04045 {
+			srgbToLinear[value] = channel / 12.92
+		} else {
+			srgbToLinear[value] = math.Pow((channel+0.055)/1.055, 2.4)
+		}
+	}
+}
+
+// linearToSrgb gamma encodes linear light value in range [0, 1]
+func linearToSrgb(value float64) byte {
+	if value <= 0.0031308 {
+		value *= 12.92
+	} else {
+		value = 1.055*math.Pow(value, 1/2.4) - 0.055
+	}
+	return clampByte(value * 255.)
+}
+
+// clampByte rounds the value and limits it to range [0, 255]
+func clampByte(value float64) byte {
+	if value <= 0 {
+		return 0
+	} else if value >= 255 {
+		return 255
+	}
+	return byte(value + 0.5)
+}
+
+// Grayscale converts the image to grayscale by the provided method
+// see (https://en.wikipedia.org/wiki/Grayscale#Converting_color_to_grayscale)
+func (b *bmp) Grayscale(method string) error {
+	// Method validation before the processing
+	if _, err := grayValue(0, 0, 0, method); err != nil {
+		return err
+	}
+
+	for rowIdx := range b.pixelArray {
+		for colIdx := uint32(0); colIdx < b.dibHeader.Width*3; colIdx += 3 {
+			pixel := b.pixelArray[rowIdx][colIdx : colIdx+3]
+			grayVal, _ := grayValue(pixel[0], pixel[1], pixel[2], method)
+
+			pixel[0] = grayVal // Blue
+			pixel[1] = grayVal // Green
+			pixel[2] = grayVal // Red
+		}
+	}
+
+	return nil
+}
+
+// grayValue returns the gray value of pixel, colors go in BGR order as in pixel array
+func grayValue(blue, green, red byte, method string) (byte, error) {
+	switch method {
+	case "rec601":
+		// Luma of analog television standard
+		return clampByte(.299*float64(red) + .587*float64(green) + .114*float64(blue)), nil
+	case "rec709":
+		// Luma of HDTV standard applied to gamma encoded values
+		return clampByte(.2126*float64(red) + .7152*float64(green) + .0722*float64(blue)), nil
+	case "average":
+		return byte((uint16(red) + uint16(green) + uint16(blue)) / 3), nil
+	case "lightness":
+		// Average of the strongest and the weakest channels
+		return byte((uint16(max(red, green, blue)) + uint16(min(red, green, blue))) / 2), nil
+	case "luminosity":
+		// Relative luminance calculated in linear light and encoded back to sRGB
+		luminance := .2126*srgbToLinear[red] + .7152*srgbToLinear[green] + .0722*srgbToLinear[blue]
+		return linearToSrgb(luminance), nil
+	case "red":
+		return red, nil
+	case "green":
+		return green, nil
+	case "blue":
+		return blue, nil
+	default:
+		return 0, ErrIncorrectGrayscaleMethod
+	}
+}
+
+// grayColorTable returns the color table of 256 gray shades
+func grayColorTable() colorTable {
+	table := make(colorTable, 256)
+	for idx := range table {
+		table[idx] = rgbQuad{Blue: byte(idx), Green: byte(idx), Red: byte(idx)}
+	}
+	return table
+}
+
+// nearest returns the index of closest color in the color table
+func (t colorTable) nearest(blue, green, red byte) byte {
+	bestIdx, bestDistance := 0, -1
+	for idx, color := range t {
+		dBlue := int(color.Blue) - int(blue)
+		dGreen := int(color.Green) - int(green)
+		dRed := int(color.Red) - int(red)
+		distance := dBlue*dBlue + dGreen*dGreen + dRed*dRed
+
+		if bestDistance == -1 || distance < bestDistance {
+			bestIdx, bestDistance = idx, distance
+		}
+		if distance == 0 {
+			break
+		}
+	}
+	return byte(bestIdx)
+}
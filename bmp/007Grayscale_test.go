@@ -0,0 +1,58 @@
+package bmp
+
+import "testing"
+
+func TestGrayscale(t *testing.T) {
+	type testData struct {
+		method    string
+		wantRed   byte
+		wantGreen byte
+	}
+
+	// Gray values of pure red and pure green
+	tests := []testData{
+		{method: "rec601", wantRed: 76, wantGreen: 150},
+		{method: "rec709", wantRed: 54, wantGreen: 182},
+		{method: "average", wantRed: 85, wantGreen: 85},
+		{method: "lightness", wantRed: 127, wantGreen: 127},
+		// Luminance is calculated in linear light, so it is brighter than rec709 luma
+		{method: "luminosity", wantRed: 127, wantGreen: 220},
+		{method: "red", wantRed: 255, wantGreen: 0},
+		{method: "green", wantRed: 0, wantGreen: 255},
+		{method: "blue", wantRed: 0, wantGreen: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method, func(t *testing.T) {
+			for _, color := range []struct {
+				pixel [3]byte
+				want  byte
+			}{{[3]byte{0, 0, 255}, test.wantRed}, {[3]byte{0, 255, 0}, test.wantGreen}} {
+				testBmp := newTestBmp(2, 2, color.pixel)
+				if err := testBmp.Grayscale(test.method); err != nil {
+					t.Fatalf("Grayscale() error = %v", err)
+				}
+				for _, row := range testBmp.pixelArray {
+					if [3]byte(row[:3]) != [3]byte{color.want, color.want, color.want} {
+						t.Fatalf("Grayscale() of BGR %v = %v, want %d", color.pixel, row[:3], color.want)
+					}
+				}
+			}
+		})
+	}
+
+	if err := newTestBmp(1, 1, [3]byte{}).Grayscale("sepia"); err != ErrIncorrectGrayscaleMethod {
+		t.Errorf("Grayscale() error = %v, want %v", err, ErrIncorrectGrayscaleMethod)
+	}
+}
+
+func TestSrgbToLinear(t *testing.T) {
+	for _, value := range []int{0, 1, 10, 128, 200, 255} {
+		if encoded := linearToSrgb(srgbToLinear[value]); int(encoded) != value {
+			t.Errorf("linearToSrgb(srgbToLinear[%d]) = %d, want %d", value, encoded, value)
+		}
+	}
+	if srgbToLinear[255] != 1 || srgbToLinear[128] < 0.21 || srgbToLinear[128] > 0.22 {
+		t.Errorf("srgbToLinear = %v and %v, want 1 and about 0.216", srgbToLinear[255], srgbToLinear[128])
+	}
+}
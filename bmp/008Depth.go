@@ -0,0 +1,60 @@
+package bmp
+
+import (
+	"errors"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectDepthValue = errors.New("Incorrect color pallete depth provided")
+)
+
+// Depth sets the color pallete of the saved image
+// value format: <bits>[:<dithering method>], the pixel array is converted to the colors of the pallete
+func (b *bmp) Depth(flagValue string) error {
+	params := utils.Split(flagValue, ":")
+	if len(params) > 2 {
+		return ErrIncorrectDepthValue
+	}
+
+	// Without dithering the nearest colors of pallete are taken
+	method := "none"
+	if len(params) == 2 {
+		method = params[1]
+	}
+
+	var target ditherTarget
+	switch params[0] {
+	case "24":
+		b.outputBitsPerPixel = 0
+		b.colorTable = nil
+		return nil
+	case "1":
+		target, _ = namedPalette("mono")
+		if method == "none" {
+			// Monochrome pallete, pixels are binarized by the middle of luma range
+			if err := b.Threshold([]string{"127"}); err != nil {
+				return err
+			}
+		}
+	case "4":
+		target, _ = namedPalette("win16")
+	case "8":
+		// 8 bit grayscale pallete, pixels are converted by luma
+		target, _ = namedPalette("gray256")
+	case "16":
+		target, _ = namedPalette("rgb555")
+	default:
+		return ErrIncorrectDepthValue
+	}
+
+	if err := b.dither(method, target); err != nil {
+		return err
+	}
+
+	b.outputBitsPerPixel = target.bitsPerPixel
+	b.colorTable = target.table
+	return nil
+}
@@ -0,0 +1,239 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectThresholdValue = errors.New("Incorrect threshold value provided")
+)
+
+// Default parameters of adaptive thresholding
+const (
+	adaptiveRadius   = 7
+	adaptiveConstant = 5
+)
+
+// Threshold binarizes the image, pixels brighter than threshold become white, others black
+// params format: <0-255> | otsu | mean[:<radius>[:<constant>]] | gaussian[:<radius>[:<constant>]]
+func (b *bmp) Threshold(params []string) error {
+	if len(params) == 0 {
+		return ErrIncorrectThresholdValue
+	}
+
+	plane := b.lumaPlane()
+
+	switch params[0] {
+	case "otsu":
+		if len(params) > 1 {
+			return ErrIncorrectThresholdValue
+		}
+		threshold := otsuThreshold(plane)
+		b.binarize(plane, func(rowIdx, colIdx int) byte { return threshold })
+	case "mean", "gaussian":
+		radius, constant := adaptiveRadius, adaptiveConstant
+		if len(params) > 1 {
+			value, ok := utils.Atoi(params[1])
+			if !ok || value < 1 {
+				return ErrIncorrectThresholdValue
+			}
+			radius = value
+		}
+		if len(params) > 2 {
+			value, ok := utils.Atoi(params[2])
+			if !ok {
+				return ErrIncorrectThresholdValue
+			}
+			constant = value
+		}
+		if len(params) > 3 {
+			return ErrIncorrectThresholdValue
+		}
+
+		// Local threshold is the weighted mean of neighborhood minus constant
+		var local [][]float64
+		if params[0] == "mean" {
+			local = boxMean(plane, radius)
+		} else {
+			local = gaussianMean(plane, radius)
+		}
+		b.binarize(plane, func(rowIdx, colIdx int) byte {
+			return clampByte(local[rowIdx][colIdx] - float64(constant))
+		})
+	default:
+		value, ok := utils.Atoi(params[0])
+		if !ok || value < 0 || value > 255 || len(params) > 1 {
+			return ErrIncorrectThresholdValue
+		}
+		b.binarize(plane, func(rowIdx, colIdx int) byte { return byte(value) })
+	}
+
+	return nil
+}
+
+// lumaPlane returns Rec. 601 luma of every pixel, rows go in the order of pixel array
+func (b *bmp) lumaPlane() [][]byte {
+	plane := make([][]byte, len(b.pixelArray))
+	for rowIdx, row := range b.pixelArray {
+		plane[rowIdx] = make([]byte, b.dibHeader.Width)
+		for colIdx := range plane[rowIdx] {
+			plane[rowIdx][colIdx], _ = grayValue(row[colIdx*3], row[colIdx*3+1], row[colIdx*3+2], "rec601")
+		}
+	}
+	return plane
+}
+
+// binarize assigns white to pixels with luma above the threshold and black to others
+func (b *bmp) binarize(plane [][]byte, threshold func(rowIdx, colIdx int) byte) {
+	for rowIdx, row := range b.pixelArray {
+		for colIdx, luma := range plane[rowIdx] {
+			value := byte(0)
+			if luma > threshold(rowIdx, colIdx) {
+				value = 255
+			}
+			row[colIdx*3] = value   // Blue
+			row[colIdx*3+1] = value // Green
+			row[colIdx*3+2] = value // Red
+		}
+	}
+}
+
+// otsuThreshold returns the threshold maximizing between-class variance of luma histogram
+// see (https://en.wikipedia.org/wiki/Otsu%27s_method)
+func otsuThreshold(plane [][]byte) byte {
+	var histogram [256]int
+	total := 0
+	for _, row := range plane {
+		for _, luma := range row {
+			histogram[luma]++
+			total++
+		}
+	}
+
+	sum := 0.
+	for value, count := range histogram {
+		sum += float64(value * count)
+	}
+
+	var threshold byte
+	var backgroundSum, bestVariance float64
+	backgroundWeight := 0
+	for value, count := range histogram {
+		backgroundWeight += count
+		if backgroundWeight == 0 {
+			continue
+		}
+		foregroundWeight := total - backgroundWeight
+		if foregroundWeight == 0 {
+			break
+		}
+
+		backgroundSum += float64(value * count)
+		backgroundMean := backgroundSum / float64(backgroundWeight)
+		foregroundMean := (sum - backgroundSum) / float64(foregroundWeight)
+
+		variance := float64(backgroundWeight) * float64(foregroundWeight) * (backgroundMean - foregroundMean) * (backgroundMean - foregroundMean)
+		if variance > bestVariance {
+			bestVariance = variance
+			threshold = byte(value)
+		}
+	}
+
+	return threshold
+}
+
+// boxMean returns the mean of every (2*radius+1)x(2*radius+1) neighborhood, calculated with integral image
+// see (https://en.wikipedia.org/wiki/Summed-area_table)
+func boxMean(plane [][]byte, radius int) [][]float64 {
+	height := len(plane)
+	if height == 0 {
+		return nil
+	}
+	width := len(plane[0])
+
+	// Integral image has additional zero row and column
+	integral := make([][]int, height+1)
+	integral[0] = make([]int, width+1)
+	for rowIdx := 0; rowIdx < height; rowIdx++ {
+		integral[rowIdx+1] = make([]int, width+1)
+		for colIdx := 0; colIdx < width; colIdx++ {
+			integral[rowIdx+1][colIdx+1] = int(plane[rowIdx][colIdx]) + integral[rowIdx][colIdx+1] + integral[rowIdx+1][colIdx] - integral[rowIdx][colIdx]
+		}
+	}
+
+	mean := make([][]float64, height)
+	for rowIdx := range mean {
+		mean[rowIdx] = make([]float64, width)
+		top, bottom := max(rowIdx-radius, 0), min(rowIdx+radius+1, height)
+		for colIdx := range mean[rowIdx] {
+			left, right := max(colIdx-radius, 0), min(colIdx+radius+1, width)
+			sum := integral[bottom][right] - integral[top][right] - integral[bottom][left] + integral[top][left]
+			mean[rowIdx][colIdx] = float64(sum) / float64((bottom-top)*(right-left))
+		}
+	}
+
+	return mean
+}
+
+// gaussianMean returns the gaussian weighted mean of every neighborhood with the radius
+// blur is separable, so rows and columns are convolved one after another
+func gaussianMean(plane [][]byte, radius int) [][]float64 {
+	height := len(plane)
+	if height == 0 {
+		return nil
+	}
+	width := len(plane[0])
+
+	// Sigma is chosen to fit the kernel as OpenCV does, weights beyond the image are never used,
+	// so the kernel is limited to the image size
+	sigma := 0.3*(float64(radius)-1) + 0.8
+	radius = min(radius, max(width, height))
+	kernel := gaussianKernel(radius, sigma)
+
+	horizontal := make([][]float64, height)
+	for rowIdx := range horizontal {
+		horizontal[rowIdx] = make([]float64, width)
+		for colIdx := range horizontal[rowIdx] {
+			sum, weight := 0., 0.
+			for offset := -radius; offset <= radius; offset++ {
+				if colIdx+offset < 0 || colIdx+offset >= width {
+					continue
+				}
+				sum += kernel[offset+radius] * float64(plane[rowIdx][colIdx+offset])
+				weight += kernel[offset+radius]
+			}
+			horizontal[rowIdx][colIdx] = sum / weight
+		}
+	}
+
+	mean := make([][]float64, height)
+	for rowIdx := range mean {
+		mean[rowIdx] = make([]float64, width)
+		for colIdx := range mean[rowIdx] {
+			sum, weight := 0., 0.
+			for offset := -radius; offset <= radius; offset++ {
+				if rowIdx+offset < 0 || rowIdx+offset >= height {
+					continue
+				}
+				sum += kernel[offset+radius] * horizontal[rowIdx+offset][colIdx]
+				weight += kernel[offset+radius]
+			}
+			mean[rowIdx][colIdx] = sum / weight
+		}
+	}
+
+	return mean
+}
+
+// gaussianKernel returns 1D gaussian weights from -radius to radius
+func gaussianKernel(radius int, sigma float64) []float64 {
+	kernel := make([]float64, 2*radius+1)
+	for offset := -radius; offset <= radius; offset++ {
+		kernel[offset+radius] = math.Exp(-float64(offset*offset) / (2 * sigma * sigma))
+	}
+	return kernel
+}
@@ -0,0 +1,63 @@
+package bmp
+
+import "testing"
+
+func TestOtsuThreshold(t *testing.T) {
+	type testData struct {
+		name  string
+		plane [][]byte
+		min   byte
+		max   byte
+	}
+
+	tests := []testData{
+		{
+			name:  "Bimodal image",
+			plane: [][]byte{{10, 12, 200, 210}, {11, 9, 205, 199}},
+			min:   12,
+			max:   198,
+		},
+		{
+			name:  "Dark text on light background",
+			plane: [][]byte{{240, 240, 240, 240}, {240, 30, 35, 240}, {240, 240, 240, 240}},
+			min:   35,
+			max:   239,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			threshold := otsuThreshold(test.plane)
+			if threshold < test.min || threshold > test.max {
+				t.Errorf("otsuThreshold() = %d, want in range [%d, %d]", threshold, test.min, test.max)
+			}
+		})
+	}
+}
+
+func TestBoxMean(t *testing.T) {
+	plane := [][]byte{{0, 0, 0}, {0, 90, 0}, {0, 0, 0}}
+
+	mean := boxMean(plane, 1)
+	if mean[1][1] != 10 {
+		t.Errorf("boxMean() center = %v, want 10", mean[1][1])
+	}
+	// Corner neighborhood is clipped to 4 pixels
+	if mean[0][0] != 22.5 {
+		t.Errorf("boxMean() corner = %v, want 22.5", mean[0][0])
+	}
+}
+
+func TestGaussianMeanLargeRadius(t *testing.T) {
+	// Radius far beyond the image averages the whole image with almost equal weights
+	plane := [][]byte{{0, 90}, {30, 0}}
+
+	mean := gaussianMean(plane, 1000000000000)
+	for rowIdx, row := range mean {
+		for colIdx, value := range row {
+			if value < 29.9 || value > 30.1 {
+				t.Errorf("gaussianMean() at (%d, %d) = %v, want about 30", colIdx, rowIdx, value)
+			}
+		}
+	}
+}
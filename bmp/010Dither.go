@@ -0,0 +1,233 @@
+package bmp
+
+import (
+	"errors"
+)
+
+// Errors
+var (
+	ErrIncorrectDitherMethod  = errors.New("Incorrect dithering method provided")
+	ErrIncorrectPaletteName   = errors.New("Incorrect palette name provided")
+	ErrIncorrectDitherPalette = errors.New("Palette of dithering is empty")
+)
+
+// Target colors of dithering
+type ditherTarget struct {
+	// Colors of palettized image, nil for 16 bits RGB 5-5-5 pallete
+	table        colorTable
+	bitsPerPixel uint16
+	// Palette contains only gray shades, so image is converted to grayscale before dithering
+	gray bool
+	// Approximate distance between neighboring colors, amplitude of ordered dithering noise
+	spread float64
+}
+
+// Error diffusion matrix entry, error is pushed to pixel with offset from the current one
+type diffusion struct {
+	dx, dy int
+	weight float64
+}
+
+// Error diffusion kernels, weights are already divided by the kernel divisor
+// see (https://tannerhelland.com/2012/12/28/dithering-eleven-algorithms-source-code.html)
+var diffusionKernels = map[string][]diffusion{
+	"floyd-steinberg": {
+		{1, 0, 7. / 16},
+		{-1, 1, 3. / 16}, {0, 1, 5. / 16}, {1, 1, 1. / 16},
+	},
+	// Only 6/8 of error is diffused, which keeps contrast of the image
+	"atkinson": {
+		{1, 0, 1. / 8}, {2, 0, 1. / 8},
+		{-1, 1, 1. / 8}, {0, 1, 1. / 8}, {1, 1, 1. / 8},
+		{0, 2, 1. / 8},
+	},
+	"jarvis-judice-ninke": {
+		{1, 0, 7. / 48}, {2, 0, 5. / 48},
+		{-2, 1, 3. / 48}, {-1, 1, 5. / 48}, {0, 1, 7. / 48}, {1, 1, 5. / 48}, {2, 1, 3. / 48},
+		{-2, 2, 1. / 48}, {-1, 2, 3. / 48}, {0, 2, 5. / 48}, {1, 2, 3. / 48}, {2, 2, 1. / 48},
+	},
+	"sierra": {
+		{1, 0, 5. / 32}, {2, 0, 3. / 32},
+		{-2, 1, 2. / 32}, {-1, 1, 4. / 32}, {0, 1, 5. / 32}, {1, 1, 4. / 32}, {2, 1, 2. / 32},
+		{-1, 2, 2. / 32}, {0, 2, 3. / 32}, {1, 2, 2. / 32},
+	},
+}
+
+// Bayer threshold matrix 8x8, values are in range [0, 64)
+// see (https://en.wikipedia.org/wiki/Ordered_dithering)
+var bayerMatrix = [8][8]float64{
+	{0, 32, 8, 40, 2, 34, 10, 42},
+	{48, 16, 56, 24, 50, 18, 58, 26},
+	{12, 44, 4, 36, 14, 46, 6, 38},
+	{60, 28, 52, 20, 62, 30, 54, 22},
+	{3, 35, 11, 43, 1, 33, 9, 41},
+	{51, 19, 59, 27, 49, 17, 57, 25},
+	{15, 47, 7, 39, 13, 45, 5, 37},
+	{63, 31, 55, 23, 61, 29, 53, 21},
+}
+
+// Dither reduces colors of image to the named palette by the dithering method
+// params format: <method>[:<palette>], image is dithered to monochrome palette by default
+func (b *bmp) Dither(params []string) error {
+	if len(params) == 0 || len(params) > 2 {
+		return ErrIncorrectDitherMethod
+	}
+
+	paletteName := "mono"
+	if len(params) == 2 {
+		paletteName = params[1]
+	}
+	target, err := namedPalette(paletteName)
+	if err != nil {
+		return err
+	}
+
+	return b.dither(params[0], target)
+}
+
+// namedPalette returns the dithering target of builtin palette
+func namedPalette(name string) (ditherTarget, error) {
+	switch name {
+	case "mono":
+		return ditherTarget{table: grayRampColorTable(2), bitsPerPixel: 1, gray: true, spread: 255}, nil
+	case "gray4":
+		return ditherTarget{table: grayRampColorTable(4), bitsPerPixel: 4, gray: true, spread: 85}, nil
+	case "gray16":
+		return ditherTarget{table: grayRampColorTable(16), bitsPerPixel: 4, gray: true, spread: 17}, nil
+	case "gray256":
+		return ditherTarget{table: grayColorTable(), bitsPerPixel: 8, gray: true, spread: 1}, nil
+	case "win16":
+		// Standard 16 colors of Windows VGA pallete
+		table := colorTable{}
+		for _, color := range [][3]byte{
+			{0, 0, 0}, {128, 0, 0}, {0, 128, 0}, {128, 128, 0}, {0, 0, 128}, {128, 0, 128}, {0, 128, 128}, {192, 192, 192},
+			{128, 128, 128}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {0, 0, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+		} {
+			table = append(table, rgbQuad{Red: color[0], Green: color[1], Blue: color[2]})
+		}
+		return ditherTarget{table: table, bitsPerPixel: 4, spread: 128}, nil
+	case "web216":
+		// 6 levels of each channel
+		table := colorTable{}
+		for red := 0; red < 6; red++ {
+			for green := 0; green < 6; green++ {
+				for blue := 0; blue < 6; blue++ {
+					table = append(table, rgbQuad{Blue: byte(blue * 51), Green: byte(green * 51), Red: byte(red * 51)})
+				}
+			}
+		}
+		return ditherTarget{table: table, bitsPerPixel: 8, spread: 51}, nil
+	case "rgb555":
+		return ditherTarget{bitsPerPixel: 16, spread: 255. / 31}, nil
+	default:
+		return ditherTarget{}, ErrIncorrectPaletteName
+	}
+}
+
+// grayRampColorTable returns the color table of evenly distributed gray shades
+func grayRampColorTable(levels int) colorTable {
+	table := make(colorTable, levels)
+	for idx := range table {
+		value := byte(idx * 255 / (levels - 1))
+		table[idx] = rgbQuad{Blue: value, Green: value, Red: value}
+	}
+	return table
+}
+
+// dither replaces every pixel with the color of target, method "none" takes the nearest color
+func (b *bmp) dither(method string, target ditherTarget) error {
+	kernel, isDiffusion := diffusionKernels[method]
+	if !isDiffusion && method != "bayer" && method != "none" {
+		return ErrIncorrectDitherMethod
+	}
+	if target.table != nil && len(target.table) == 0 {
+		return ErrIncorrectDitherPalette
+	}
+
+	if target.gray {
+		if err := b.Grayscale("rec601"); err != nil {
+			return err
+		}
+	}
+
+	quantize := target.quantizer()
+	width, height := int(b.dibHeader.Width), len(b.pixelArray)
+
+	switch {
+	case method == "none":
+		for y := 0; y < height; y++ {
+			row := b.row(y)
+			for x := 0; x < width; x++ {
+				row[x*3], row[x*3+1], row[x*3+2] = quantize(float64(row[x*3]), float64(row[x*3+1]), float64(row[x*3+2]))
+			}
+		}
+	case method == "bayer":
+		for y := 0; y < height; y++ {
+			row := b.row(y)
+			for x := 0; x < width; x++ {
+				// Threshold is shifted to be centered around zero
+				offset := ((bayerMatrix[y%8][x%8]+0.5)/64 - 0.5) * target.spread
+				row[x*3], row[x*3+1], row[x*3+2] = quantize(float64(row[x*3])+offset, float64(row[x*3+1])+offset, float64(row[x*3+2])+offset)
+			}
+		}
+	default:
+		// Accumulated color values with diffused error, rows go from the top of the image
+		buffer := make([][]float64, height)
+		for y := range buffer {
+			row := b.row(y)
+			buffer[y] = make([]float64, width*3)
+			for idx := range buffer[y] {
+				buffer[y][idx] = float64(row[idx])
+			}
+		}
+
+		for y := 0; y < height; y++ {
+			row := b.row(y)
+			for x := 0; x < width; x++ {
+				old := buffer[y][x*3 : x*3+3]
+				blue, green, red := quantize(old[0], old[1], old[2])
+				row[x*3], row[x*3+1], row[x*3+2] = blue, green, red
+
+				// Quantization error
+				errBlue, errGreen, errRed := old[0]-float64(blue), old[1]-float64(green), old[2]-float64(red)
+
+				for _, entry := range kernel {
+					if x+entry.dx < 0 || x+entry.dx >= width || y+entry.dy >= height {
+						continue
+					}
+					neighbor := buffer[y+entry.dy][(x+entry.dx)*3:]
+					neighbor[0] += errBlue * entry.weight
+					neighbor[1] += errGreen * entry.weight
+					neighbor[2] += errRed * entry.weight
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// quantizer returns the function which maps color to the closest color of target
+func (t ditherTarget) quantizer() func(blue, green, red float64) (byte, byte, byte) {
+	if t.table == nil {
+		// 5 bits per channel, values are stored so that saving shifts them back exactly
+		return func(blue, green, red float64) (byte, byte, byte) {
+			level := func(value float64) byte {
+				return byte((int(clampByte(value))*31 + 127) / 255 * 255 / 31)
+			}
+			return level(blue), level(green), level(red)
+		}
+	}
+
+	// Already found nearest colors
+	nearest := make(map[[3]byte]rgbQuad)
+	return func(blue, green, red float64) (byte, byte, byte) {
+		key := [3]byte{clampByte(blue), clampByte(green), clampByte(red)}
+		color, ok := nearest[key]
+		if !ok {
+			color = t.table[t.table.nearest(key[0], key[1], key[2])]
+			nearest[key] = color
+		}
+		return color.Blue, color.Green, color.Red
+	}
+}
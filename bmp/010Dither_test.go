@@ -0,0 +1,88 @@
+package bmp
+
+import "testing"
+
+func TestDitherGrayField(t *testing.T) {
+	// 50% gray field dithered to monochrome must have about half of pixels lit
+	for _, method := range []string{"floyd-steinberg", "atkinson", "jarvis-judice-ninke", "sierra", "bayer"} {
+		t.Run(method, func(t *testing.T) {
+			testBmp := newTestBmp(32, 32, [3]byte{128, 128, 128})
+			if err := testBmp.Dither([]string{method}); err != nil {
+				t.Fatalf("Dither() error = %v", err)
+			}
+
+			lit := 0
+			for _, row := range testBmp.pixelArray {
+				for colIdx := 0; colIdx < 32; colIdx++ {
+					switch [3]byte(row[colIdx*3 : colIdx*3+3]) {
+					case [3]byte{255, 255, 255}:
+						lit++
+					case [3]byte{0, 0, 0}:
+					default:
+						t.Fatalf("Dither() pixel = %v, want black or white", row[colIdx*3:colIdx*3+3])
+					}
+				}
+			}
+			if lit < 32*32*45/100 || lit > 32*32*55/100 {
+				t.Errorf("Dither() lit %d of %d pixels, want about half", lit, 32*32)
+			}
+		})
+	}
+}
+
+func TestDitherBayerMatrix(t *testing.T) {
+	// Pixels of 50% gray are lit where the threshold is in the upper half of the matrix
+	testBmp := newTestBmp(16, 16, [3]byte{128, 128, 128})
+	if err := testBmp.Dither([]string{"bayer", "mono"}); err != nil {
+		t.Fatalf("Dither() error = %v", err)
+	}
+	for y := 0; y < 16; y++ {
+		row := testBmp.row(y)
+		for x := 0; x < 16; x++ {
+			want := byte(0)
+			if bayerMatrix[y%8][x%8] >= 32 {
+				want = 255
+			}
+			if row[x*3] != want {
+				t.Fatalf("Dither() pixel (%d, %d) = %d, want %d", x, y, row[x*3], want)
+			}
+		}
+	}
+}
+
+func TestDitherPalette(t *testing.T) {
+	for _, palette := range []string{"gray4", "gray16", "win16", "web216"} {
+		for _, method := range []string{"none", "floyd-steinberg", "bayer"} {
+			t.Run(palette+" "+method, func(t *testing.T) {
+				// Gradient of red and green with constant blue
+				testBmp := newTestBmp(24, 24, [3]byte{90, 0, 0})
+				for y := 0; y < 24; y++ {
+					row := testBmp.row(y)
+					for x := 0; x < 24; x++ {
+						row[x*3+1], row[x*3+2] = byte(x*11), byte(y*11)
+					}
+				}
+				if err := testBmp.Dither([]string{method, palette}); err != nil {
+					t.Fatalf("Dither() error = %v", err)
+				}
+
+				target, _ := namedPalette(palette)
+				colors := map[[3]byte]bool{}
+				for _, color := range target.table {
+					colors[[3]byte{color.Blue, color.Green, color.Red}] = true
+				}
+				for _, row := range testBmp.pixelArray {
+					for colIdx := 0; colIdx < 24; colIdx++ {
+						if pixel := [3]byte(row[colIdx*3 : colIdx*3+3]); !colors[pixel] {
+							t.Fatalf("Dither() pixel %v is not in the palette", pixel)
+						}
+					}
+				}
+			})
+		}
+	}
+
+	if err := newTestBmp(1, 1, [3]byte{}).Dither([]string{"bayer", "cga"}); err != ErrIncorrectPaletteName {
+		t.Errorf("Dither() error = %v, want %v", err, ErrIncorrectPaletteName)
+	}
+}
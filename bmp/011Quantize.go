@@ -0,0 +1,421 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+	"os"
+	"sort"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectQuantizeValue  = errors.New("Incorrect quantize value provided")
+	ErrIncorrectPaletteFile    = errors.New("Palette file is corrupted or has no colors")
+	ErrTooManyPaletteColors    = errors.New("Palette has more than 256 colors")
+	ErrIncorrectQuantizeMethod = errors.New("Incorrect quantization method provided")
+)
+
+// Default parameters of quantization
+const (
+	quantizeColors     = 256
+	kmeansIterations   = 10
+	octreeDepth        = 8
+	quantizeMethod     = "median-cut"
+	quantizeDitherNone = "none"
+)
+
+// Unique color of image and the number of its pixels
+type colorCount struct {
+	color [3]byte // BGR order as in pixel array
+	count int
+}
+
+// Quantize reduces the image to the palette of at most N colors and saves it palettized
+// value format: colors:<2-256>,method=<median-cut|octree|kmeans>,dither=<method> or palette:<file>,dither=<method>
+func (b *bmp) Quantize(flagValue string) error {
+	options, ok := utils.ParseOptions(flagValue)
+	if !ok {
+		return ErrIncorrectQuantizeValue
+	}
+
+	colors, method, dither := quantizeColors, quantizeMethod, quantizeDitherNone
+	for key, value := range options {
+		switch key {
+		case "colors":
+			colors, ok = utils.Atoi(value)
+			if !ok || colors < 2 || colors > 256 {
+				return ErrIncorrectQuantizeValue
+			}
+		case "method":
+			method = value
+		case "dither":
+			dither = value
+		case "palette":
+		default:
+			return ErrIncorrectQuantizeValue
+		}
+	}
+
+	var table colorTable
+	if fileName, ok := options["palette"]; ok {
+		var err error
+		if table, err = loadPalette(fileName); err != nil {
+			return err
+		}
+	} else {
+		histogram := b.colorHistogram()
+		switch method {
+		case "median-cut":
+			table = medianCut(histogram, colors)
+		case "octree":
+			table = octreeQuantize(histogram, colors)
+		case "kmeans":
+			table = kmeansQuantize(histogram, colors)
+		default:
+			return ErrIncorrectQuantizeMethod
+		}
+	}
+
+	// The smallest pallete which fits all colors
+	target := ditherTarget{table: table, bitsPerPixel: 8, spread: 255 / math.Cbrt(float64(len(table)))}
+	if len(table) <= 2 {
+		target.bitsPerPixel = 1
+	} else if len(table) <= 16 {
+		target.bitsPerPixel = 4
+	}
+
+	if err := b.dither(dither, target); err != nil {
+		return err
+	}
+
+	b.outputBitsPerPixel = target.bitsPerPixel
+	b.colorTable = target.table
+	return nil
+}
+
+// colorHistogram returns the unique colors of image with their pixel counts
+func (b *bmp) colorHistogram() []colorCount {
+	counts := make(map[[3]byte]int)
+	for _, row := range b.pixelArray {
+		for colIdx := uint32(0); colIdx < b.dibHeader.Width; colIdx++ {
+			counts[[3]byte{row[colIdx*3], row[colIdx*3+1], row[colIdx*3+2]}]++
+		}
+	}
+
+	histogram := make([]colorCount, 0, len(counts))
+	for color, count := range counts {
+		histogram = append(histogram, colorCount{color, count})
+	}
+	// Map iteration order is random, sorting makes quantization reproducible
+	sort.Slice(histogram, func(i, j int) bool {
+		for channel := 0; channel < 3; channel++ {
+			if histogram[i].color[channel] != histogram[j].color[channel] {
+				return histogram[i].color[channel] < histogram[j].color[channel]
+			}
+		}
+		return false
+	})
+
+	return histogram
+}
+
+// averageColor returns the color table entry of the weighted mean of colors
+func averageColor(colors []colorCount) rgbQuad {
+	var sums [3]int
+	total := 0
+	for _, entry := range colors {
+		for channel := range sums {
+			sums[channel] += int(entry.color[channel]) * entry.count
+		}
+		total += entry.count
+	}
+	if total == 0 {
+		return rgbQuad{}
+	}
+	return rgbQuad{
+		Blue:  byte((sums[0] + total/2) / total),
+		Green: byte((sums[1] + total/2) / total),
+		Red:   byte((sums[2] + total/2) / total),
+	}
+}
+
+// medianCut splits the color space box with the widest channel range at the median pixel until there are N boxes
+// see (https://en.wikipedia.org/wiki/Median_cut)
+func medianCut(histogram []colorCount, colors int) colorTable {
+	boxes := [][]colorCount{histogram}
+
+	for len(boxes) < colors {
+		// Box with the widest channel range, weighted by the number of pixels
+		boxIdx, widestChannel, widestScore := -1, 0, 0
+		for idx, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			channel, channelRange := widestRange(box)
+			pixels := 0
+			for _, entry := range box {
+				pixels += entry.count
+			}
+			if score := channelRange * int(math.Sqrt(float64(pixels))); score > widestScore {
+				boxIdx, widestChannel, widestScore = idx, channel, score
+			}
+		}
+		// Every box contains a single color
+		if boxIdx == -1 {
+			break
+		}
+
+		box := boxes[boxIdx]
+		sort.Slice(box, func(i, j int) bool { return box[i].color[widestChannel] < box[j].color[widestChannel] })
+
+		// Median by the number of pixels, both halves keep at least one color
+		total := 0
+		for _, entry := range box {
+			total += entry.count
+		}
+		medianIdx, accumulated := 1, box[0].count
+		for medianIdx < len(box)-1 && accumulated < total/2 {
+			accumulated += box[medianIdx].count
+			medianIdx++
+		}
+
+		boxes[boxIdx] = box[:medianIdx]
+		boxes = append(boxes, box[medianIdx:])
+	}
+
+	table := make(colorTable, 0, len(boxes))
+	for _, box := range boxes {
+		table = append(table, averageColor(box))
+	}
+	return table
+}
+
+// widestRange returns the channel with the largest difference of values in colors
+func widestRange(colors []colorCount) (channel, channelRange int) {
+	for ch := 0; ch < 3; ch++ {
+		low, high := 255, 0
+		for _, entry := range colors {
+			low = min(low, int(entry.color[ch]))
+			high = max(high, int(entry.color[ch]))
+		}
+		if high-low > channelRange || ch == 0 {
+			channel, channelRange = ch, high-low
+		}
+	}
+	return
+}
+
+// Node of color octree, every level splits the colors by one bit of each channel
+type octreeNode struct {
+	children [8]*octreeNode
+	sums     [3]int
+	count    int
+	leaf     bool
+}
+
+// octreeQuantize builds the octree of colors and merges the least populated deepest nodes until N leaves remain
+// see (https://en.wikipedia.org/wiki/Octree#Color_quantization)
+func octreeQuantize(histogram []colorCount, colors int) colorTable {
+	root := &octreeNode{}
+	// Nodes with children on every level, candidates for reduction
+	levels := make([][]*octreeNode, octreeDepth)
+	leaves := 0
+
+	for _, entry := range histogram {
+		node := root
+		for level := 0; level < octreeDepth; level++ {
+			shift := 7 - level
+			childIdx := (entry.color[2]>>shift&1)<<2 | (entry.color[1]>>shift&1)<<1 | entry.color[0]>>shift&1
+			if node.children[childIdx] == nil {
+				node.children[childIdx] = &octreeNode{leaf: level == octreeDepth-1}
+				if level == octreeDepth-1 {
+					leaves++
+				} else {
+					levels[level+1] = append(levels[level+1], node.children[childIdx])
+				}
+			}
+			node = node.children[childIdx]
+		}
+		for channel := range node.sums {
+			node.sums[channel] += int(entry.color[channel]) * entry.count
+		}
+		node.count += entry.count
+	}
+	levels[0] = []*octreeNode{root}
+
+	// Reduction of the deepest level first
+	for level := octreeDepth - 1; level >= 0 && leaves > colors; level-- {
+		nodes := levels[level]
+		// Subtree pixel counts are needed to merge the least populated nodes first
+		counts := make(map[*octreeNode]int, len(nodes))
+		for _, node := range nodes {
+			counts[node] = node.subtreeCount()
+		}
+		sort.SliceStable(nodes, func(i, j int) bool { return counts[nodes[i]] < counts[nodes[j]] })
+
+		for _, node := range nodes {
+			if leaves <= colors {
+				break
+			}
+			// Children are leaves at this point, ordered from the least populated
+			children := []int{}
+			for idx, child := range node.children {
+				if child != nil {
+					children = append(children, idx)
+				}
+			}
+			sort.SliceStable(children, func(i, j int) bool {
+				return node.children[children[i]].count < node.children[children[j]].count
+			})
+
+			// Merging all children would leave less than N colors, so only the least populated are combined
+			if excess := leaves - colors; len(children)-1 > excess {
+				kept := node.children[children[excess]]
+				for _, idx := range children[:excess] {
+					for channel := range kept.sums {
+						kept.sums[channel] += node.children[idx].sums[channel]
+					}
+					kept.count += node.children[idx].count
+					node.children[idx] = nil
+				}
+				leaves -= excess
+				break
+			}
+
+			for _, idx := range children {
+				for channel := range node.sums {
+					node.sums[channel] += node.children[idx].sums[channel]
+				}
+				node.count += node.children[idx].count
+				node.children[idx] = nil
+			}
+			node.leaf = true
+			leaves -= len(children) - 1
+		}
+	}
+
+	table := colorTable{}
+	root.collectLeaves(&table)
+	return table
+}
+
+// subtreeCount returns the number of pixels in the node and its children
+func (n *octreeNode) subtreeCount() int {
+	count := n.count
+	for _, child := range n.children {
+		if child != nil {
+			count += child.subtreeCount()
+		}
+	}
+	return count
+}
+
+// collectLeaves appends the average colors of leaves to the table
+func (n *octreeNode) collectLeaves(table *colorTable) {
+	if n.leaf {
+		if n.count > 0 {
+			*table = append(*table, rgbQuad{
+				Blue:  byte(n.sums[0] / n.count),
+				Green: byte(n.sums[1] / n.count),
+				Red:   byte(n.sums[2] / n.count),
+			})
+		}
+		return
+	}
+	for _, child := range n.children {
+		if child != nil {
+			child.collectLeaves(table)
+		}
+	}
+}
+
+// kmeansQuantize refines the median cut palette by Lloyd's iterations over unique colors
+// see (https://en.wikipedia.org/wiki/K-means_clustering)
+func kmeansQuantize(histogram []colorCount, colors int) colorTable {
+	table := medianCut(histogram, colors)
+	clusters := make([][]colorCount, len(table))
+
+	for iteration := 0; iteration < kmeansIterations; iteration++ {
+		for idx := range clusters {
+			clusters[idx] = clusters[idx][:0]
+		}
+		for _, entry := range histogram {
+			idx := table.nearest(entry.color[0], entry.color[1], entry.color[2])
+			clusters[idx] = append(clusters[idx], entry)
+		}
+
+		changed := false
+		for idx, cluster := range clusters {
+			// Empty cluster keeps its previous center
+			if len(cluster) == 0 {
+				continue
+			}
+			if center := averageColor(cluster); center != table[idx] {
+				table[idx] = center
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	return table
+}
+
+// loadPalette reads the color table from GIMP palette (.gpl) or the list of hex colors, one per line
+func loadPalette(fileName string) (colorTable, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := utils.Split(string(content), "\n")
+	isGimp := len(lines) > 0 && utils.HasPrefix("GIMP Palette", lines[0])
+	if isGimp {
+		lines = lines[1:]
+	}
+
+	table := colorTable{}
+	for _, line := range lines {
+		fields := utils.Fields(line)
+		// Empty lines and comments are skipped
+		if len(fields) == 0 || fields[0][0] == '#' && isGimp || fields[0][0] == ';' {
+			continue
+		}
+
+		if isGimp {
+			// Name and Columns attributes of GIMP palette
+			if fields[0][len(fields[0])-1] == ':' {
+				continue
+			}
+			if len(fields) < 3 {
+				return nil, ErrIncorrectPaletteFile
+			}
+			var channels [3]byte
+			for idx := range channels {
+				value, ok := utils.Atoi(fields[idx])
+				if !ok || value < 0 || value > 255 {
+					return nil, ErrIncorrectPaletteFile
+				}
+				channels[idx] = byte(value)
+			}
+			table = append(table, rgbQuad{Red: channels[0], Green: channels[1], Blue: channels[2]})
+		} else {
+			red, green, blue, ok := utils.ParseHexColor(fields[0])
+			if !ok {
+				return nil, ErrIncorrectPaletteFile
+			}
+			table = append(table, rgbQuad{Red: red, Green: green, Blue: blue})
+		}
+	}
+
+	if len(table) == 0 {
+		return nil, ErrIncorrectPaletteFile
+	} else if len(table) > 256 {
+		return nil, ErrTooManyPaletteColors
+	}
+	return table, nil
+}
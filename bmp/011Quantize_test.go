@@ -0,0 +1,90 @@
+package bmp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuantizers(t *testing.T) {
+	// Gradient of all red and green values
+	histogram := []colorCount{}
+	for red := 0; red < 256; red += 5 {
+		for green := 0; green < 256; green += 5 {
+			histogram = append(histogram, colorCount{color: [3]byte{0, byte(green), byte(red)}, count: 1 + red%3})
+		}
+	}
+
+	quantizers := map[string]func([]colorCount, int) colorTable{
+		"median-cut": medianCut,
+		"octree":     octreeQuantize,
+		"kmeans":     kmeansQuantize,
+	}
+
+	for name, quantize := range quantizers {
+		for _, colors := range []int{2, 16, 256} {
+			table := quantize(append([]colorCount{}, histogram...), colors)
+			if len(table) == 0 || len(table) > colors {
+				t.Errorf("%s with %d colors returned %d colors", name, colors, len(table))
+			}
+			// Small palettes must be fully used for the rich image
+			if colors <= 16 && len(table) != colors {
+				t.Errorf("%s with %d colors returned %d colors", name, colors, len(table))
+			}
+		}
+	}
+}
+
+func TestLoadPalette(t *testing.T) {
+	type testData struct {
+		name    string
+		content string
+		colors  colorTable
+		err     error
+	}
+
+	tests := []testData{
+		{
+			name:    "GIMP palette",
+			content: "GIMP Palette\nName: Test\nColumns: 2\n#\n255   0   0\tRed\n  0 128 255 Azure\n",
+			colors:  colorTable{{Red: 255}, {Green: 128, Blue: 255}},
+		},
+		{
+			name:    "Hex list",
+			content: "#ff0000\n\n0080FF\n",
+			colors:  colorTable{{Red: 255}, {Green: 128, Blue: 255}},
+		},
+		{
+			name:    "Corrupted hex list",
+			content: "#ff00\n",
+			err:     ErrIncorrectPaletteFile,
+		},
+		{
+			name:    "Empty palette",
+			content: "GIMP Palette\nName: Empty\n",
+			err:     ErrIncorrectPaletteFile,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "palette")
+			if err := os.WriteFile(fileName, []byte(test.content), 0o644); err != nil {
+				t.Fatalf("Error while writing %s: %s\n", fileName, err)
+			}
+
+			table, err := loadPalette(fileName)
+			if err != test.err {
+				t.Fatalf("loadPalette() error = %v, wantErr %v", err, test.err)
+			}
+			if len(table) != len(test.colors) {
+				t.Fatalf("loadPalette() = %v, want %v", table, test.colors)
+			}
+			for idx := range table {
+				if table[idx] != test.colors[idx] {
+					t.Errorf("loadPalette() = %v, want %v", table, test.colors)
+				}
+			}
+		})
+	}
+}
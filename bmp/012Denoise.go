@@ -0,0 +1,258 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectDenoiseValue = errors.New("Incorrect denoising filter parameters provided")
+)
+
+// Default parameters of denoising filters
+const (
+	denoiseRadius     = 2
+	bilateralStrength = 30
+	nlmRadius         = 5
+	nlmPatchRadius    = 1
+	nlmStrength       = 10
+)
+
+// Denoise applies the denoising filter: median, mode, bilateral or nlm (non-local means)
+// params format: <radius>[:<strength>], strength is used only by bilateral and nlm
+func (b *bmp) Denoise(filter string, params []string) error {
+	radius, strength := denoiseRadius, 0
+	switch filter {
+	case "bilateral":
+		strength = bilateralStrength
+	case "nlm":
+		radius, strength = nlmRadius, nlmStrength
+	}
+
+	if len(params) > 2 || len(params) == 2 && (filter == "median" || filter == "mode") {
+		return ErrIncorrectDenoiseValue
+	}
+	if len(params) > 0 {
+		value, ok := utils.Atoi(params[0])
+		if !ok || value < 1 {
+			return ErrIncorrectDenoiseValue
+		}
+		radius = value
+	}
+	if len(params) > 1 {
+		value, ok := utils.Atoi(params[1])
+		if !ok || value < 1 {
+			return ErrIncorrectDenoiseValue
+		}
+		strength = value
+	}
+
+	switch filter {
+	case "median":
+		b.rankFilter(radius, histogramMedian)
+	case "mode":
+		b.rankFilter(radius, histogramMode)
+	case "bilateral":
+		b.bilateral(radius, float64(strength))
+	case "nlm":
+		b.nonLocalMeans(radius, float64(strength))
+	default:
+		return ErrIncorrectDenoiseValue
+	}
+
+	return nil
+}
+
+// rankFilter replaces every channel value with the value picked from the histogram of neighborhood,
+// histograms slide along the row, so only the entering and leaving columns are updated
+// see (https://en.wikipedia.org/wiki/Median_filter#Two-dimensional_median_filter_pseudo_code)
+func (b *bmp) rankFilter(radius int, pick func(histogram *[256]int, count int) byte) {
+	source := b.copyPixelArray()
+	width := int(b.dibHeader.Width)
+
+	for rowIdx := range b.pixelArray {
+		var histograms [3][256]int
+		top, bottom, left, right := b.window(rowIdx, 0, radius)
+		for y := top; y < bottom; y++ {
+			for x := left; x < right; x++ {
+				for channel := range histograms {
+					histograms[channel][source[y][x*3+channel]]++
+				}
+			}
+		}
+		count := (bottom - top) * (right - left)
+
+		for colIdx := 0; colIdx < width; colIdx++ {
+			for channel := range histograms {
+				b.pixelArray[rowIdx][colIdx*3+channel] = pick(&histograms[channel], count)
+			}
+
+			// Column leaving the window
+			if leaving := colIdx - radius; leaving >= 0 {
+				for y := top; y < bottom; y++ {
+					for channel := range histograms {
+						histograms[channel][source[y][leaving*3+channel]]--
+					}
+				}
+				count -= bottom - top
+			}
+			// Column entering the window
+			if entering := colIdx + radius + 1; entering < width {
+				for y := top; y < bottom; y++ {
+					for channel := range histograms {
+						histograms[channel][source[y][entering*3+channel]]++
+					}
+				}
+				count += bottom - top
+			}
+		}
+	}
+}
+
+// histogramMedian returns the middle value of histogram
+func histogramMedian(histogram *[256]int, count int) byte {
+	accumulated := 0
+	for value, number := range histogram {
+		accumulated += number
+		if accumulated > count/2 {
+			return byte(value)
+		}
+	}
+	return 255
+}
+
+// histogramMode returns the most frequent value of histogram, the smallest one on ties
+func histogramMode(histogram *[256]int, count int) byte {
+	mode := 0
+	for value, number := range histogram {
+		if number > histogram[mode] {
+			mode = value
+		}
+	}
+	return byte(mode)
+}
+
+// bilateral averages the neighborhood weighted by both spatial distance and color difference,
+// so edges with strong color difference are preserved
+// see (https://en.wikipedia.org/wiki/Bilateral_filter)
+func (b *bmp) bilateral(radius int, sigmaColor float64) {
+	source := b.copyPixelArray()
+	sigmaSpace := max(float64(radius)/2, 1)
+	// Neighborhood is limited to the image size as the window of rank filters
+	radius = min(radius, max(len(b.pixelArray), int(b.dibHeader.Width)))
+
+	// Weights of spatial distances
+	spatial := make([][]float64, 2*radius+1)
+	for dy := -radius; dy <= radius; dy++ {
+		spatial[dy+radius] = make([]float64, 2*radius+1)
+		for dx := -radius; dx <= radius; dx++ {
+			spatial[dy+radius][dx+radius] = math.Exp(-float64(dx*dx+dy*dy) / (2 * sigmaSpace * sigmaSpace))
+		}
+	}
+	// Weights of squared color distances
+	colorWeights := make([]float64, 3*255*255+1)
+	for distance := range colorWeights {
+		colorWeights[distance] = math.Exp(-float64(distance) / (2 * sigmaColor * sigmaColor))
+	}
+
+	for rowIdx := range b.pixelArray {
+		for colIdx := 0; colIdx < int(b.dibHeader.Width); colIdx++ {
+			center := source[rowIdx][colIdx*3 : colIdx*3+3]
+			var sums [3]float64
+			weightSum := 0.
+
+			top, bottom, left, right := b.window(rowIdx, colIdx, radius)
+			for y := top; y < bottom; y++ {
+				for x := left; x < right; x++ {
+					pixel := source[y][x*3 : x*3+3]
+					distance := 0
+					for channel := range sums {
+						diff := int(pixel[channel]) - int(center[channel])
+						distance += diff * diff
+					}
+
+					weight := spatial[y-rowIdx+radius][x-colIdx+radius] * colorWeights[distance]
+					for channel := range sums {
+						sums[channel] += weight * float64(pixel[channel])
+					}
+					weightSum += weight
+				}
+			}
+
+			for channel := range sums {
+				b.pixelArray[rowIdx][colIdx*3+channel] = clampByte(sums[channel] / weightSum)
+			}
+		}
+	}
+}
+
+// nonLocalMeans averages pixels of search window weighted by the similarity of their surrounding patches,
+// patch distances of every offset are summed for all pixels at once with integral image
+// see (https://en.wikipedia.org/wiki/Non-local_means)
+func (b *bmp) nonLocalMeans(radius int, strength float64) {
+	source := b.copyPixelArray()
+	height, width := len(b.pixelArray), int(b.dibHeader.Width)
+	patchSize := float64(3 * (2*nlmPatchRadius + 1) * (2*nlmPatchRadius + 1))
+
+	sums := make([][]float64, height)
+	weightSums := make([][]float64, height)
+	for rowIdx := range sums {
+		sums[rowIdx] = make([]float64, width*3)
+		weightSums[rowIdx] = make([]float64, width)
+	}
+
+	// Integral image of squared differences between pixel and pixel with offset, borders are clamped
+	integral := make([][]int, height+1)
+	for rowIdx := range integral {
+		integral[rowIdx] = make([]int, width+1)
+	}
+
+	// Offsets of search window are limited to the image size
+	for dy := -min(radius, height-1); dy <= min(radius, height-1); dy++ {
+		for dx := -min(radius, width-1); dx <= min(radius, width-1); dx++ {
+			for rowIdx := 0; rowIdx < height; rowIdx++ {
+				shiftedRow := source[min(max(rowIdx+dy, 0), height-1)]
+				for colIdx := 0; colIdx < width; colIdx++ {
+					shiftedCol := min(max(colIdx+dx, 0), width-1)
+					distance := 0
+					for channel := 0; channel < 3; channel++ {
+						diff := int(source[rowIdx][colIdx*3+channel]) - int(shiftedRow[shiftedCol*3+channel])
+						distance += diff * diff
+					}
+					integral[rowIdx+1][colIdx+1] = distance + integral[rowIdx][colIdx+1] + integral[rowIdx+1][colIdx] - integral[rowIdx][colIdx]
+				}
+			}
+
+			for rowIdx := 0; rowIdx < height; rowIdx++ {
+				// Pixel with offset must be inside the image
+				if rowIdx+dy < 0 || rowIdx+dy >= height {
+					continue
+				}
+				shiftedRow := source[rowIdx+dy]
+				top, bottom := max(rowIdx-nlmPatchRadius, 0), min(rowIdx+nlmPatchRadius+1, height)
+				for colIdx := max(-dx, 0); colIdx < min(width-dx, width); colIdx++ {
+					left, right := max(colIdx-nlmPatchRadius, 0), min(colIdx+nlmPatchRadius+1, width)
+					distance := integral[bottom][right] - integral[top][right] - integral[bottom][left] + integral[top][left]
+					// Patches clipped by borders are normalized by the full patch size
+					weight := math.Exp(-float64(distance) / patchSize / (strength * strength))
+
+					for channel := 0; channel < 3; channel++ {
+						sums[rowIdx][colIdx*3+channel] += weight * float64(shiftedRow[(colIdx+dx)*3+channel])
+					}
+					weightSums[rowIdx][colIdx] += weight
+				}
+			}
+		}
+	}
+
+	for rowIdx := range b.pixelArray {
+		for colIdx := 0; colIdx < width; colIdx++ {
+			for channel := 0; channel < 3; channel++ {
+				b.pixelArray[rowIdx][colIdx*3+channel] = clampByte(sums[rowIdx][colIdx*3+channel] / weightSums[rowIdx][colIdx])
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package bmp
+
+import "testing"
+
+func TestDenoiseImpulse(t *testing.T) {
+	// Isolated impulses are replaced by the flat surrounding of gray 100
+	for _, params := range [][]string{{"median", "1"}, {"mode", "1"}, {"median"}, {"mode"}} {
+		t.Run(params[0], func(t *testing.T) {
+			testBmp := newTestBmp(9, 9, [3]byte{100, 100, 100})
+			copy(testBmp.pixelArray[4][4*3:], []byte{255, 255, 255})
+			copy(testBmp.pixelArray[1][6*3:], []byte{0, 0, 0})
+			if err := testBmp.Denoise(params[0], params[1:]); err != nil {
+				t.Fatalf("Denoise() error = %v", err)
+			}
+			for rowIdx, row := range testBmp.pixelArray {
+				for colIdx := 0; colIdx < 9; colIdx++ {
+					if [3]byte(row[colIdx*3:colIdx*3+3]) != [3]byte{100, 100, 100} {
+						t.Fatalf("Denoise() pixel (%d, %d) = %v, want flat 100", colIdx, rowIdx, row[colIdx*3:colIdx*3+3])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDenoiseEdge(t *testing.T) {
+	// Black and white halves with checkered noise of amplitude 8, edge is kept while the noise is smoothed
+	for _, params := range [][]string{{"bilateral", "2", "30"}, {"nlm", "3", "10"}} {
+		t.Run(params[0], func(t *testing.T) {
+			testBmp := newTestBmp(16, 8, [3]byte{})
+			for rowIdx, row := range testBmp.pixelArray {
+				for colIdx := 0; colIdx < 16; colIdx++ {
+					value := 20
+					if colIdx >= 8 {
+						value = 230
+					}
+					if (rowIdx+colIdx)%2 == 0 {
+						value += 8
+					} else {
+						value -= 8
+					}
+					row[colIdx*3], row[colIdx*3+1], row[colIdx*3+2] = byte(value), byte(value), byte(value)
+				}
+			}
+			if err := testBmp.Denoise(params[0], params[1:]); err != nil {
+				t.Fatalf("Denoise() error = %v", err)
+			}
+
+			for rowIdx, row := range testBmp.pixelArray {
+				for colIdx := 0; colIdx < 16; colIdx++ {
+					mean := 20
+					if colIdx >= 8 {
+						mean = 230
+					}
+					if diff := int(row[colIdx*3]) - mean; diff <= -8 || diff >= 8 {
+						t.Fatalf("Denoise() pixel (%d, %d) = %d, want closer than 8 to %d", colIdx, rowIdx, row[colIdx*3], mean)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDenoiseLargeRadius(t *testing.T) {
+	// Radius far beyond the image is limited to the image, flat image stays the same
+	for _, params := range [][]string{{"median", "1000000000000"}, {"bilateral", "1000000000000", "5"}, {"nlm", "1000000000000"}} {
+		t.Run(params[0], func(t *testing.T) {
+			testBmp := newTestBmp(5, 3, [3]byte{40, 80, 120})
+			if err := testBmp.Denoise(params[0], params[1:]); err != nil {
+				t.Fatalf("Denoise() error = %v", err)
+			}
+			for _, row := range testBmp.pixelArray {
+				if [3]byte(row[:3]) != [3]byte{40, 80, 120} {
+					t.Fatalf("Denoise() pixel = %v, want the fill color", row[:3])
+				}
+			}
+		})
+	}
+}
+
+func TestDenoiseValue(t *testing.T) {
+	testBmp := newTestBmp(4, 4, [3]byte{})
+	for _, params := range [][]string{{"0"}, {"2", "x"}, {"1", "2", "3"}} {
+		if err := testBmp.Denoise("bilateral", params); err != ErrIncorrectDenoiseValue {
+			t.Errorf("Denoise(%v) error = %v, want %v", params, err, ErrIncorrectDenoiseValue)
+		}
+	}
+	if err := testBmp.Denoise("median", []string{"1", "10"}); err != ErrIncorrectDenoiseValue {
+		t.Errorf("Denoise() error = %v, want %v", err, ErrIncorrectDenoiseValue)
+	}
+}
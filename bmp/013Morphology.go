@@ -0,0 +1,256 @@
+package bmp
+
+import (
+	"errors"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectMorphologyValue = errors.New("Incorrect morphology value provided")
+	ErrIncorrectStructElement   = errors.New("Incorrect structuring element provided")
+)
+
+// Default parameters of morphology
+const (
+	morphologyElement    = "square"
+	morphologyRadius     = 1
+	morphologyIterations = 1
+)
+
+// Structuring element is the list of offsets from its origin in the center
+type structElement struct {
+	offsets [][2]int // pairs of dx, dy
+	// Square element is separable, so it's applied as a horizontal and a vertical line
+	square bool
+	radius int
+}
+
+// Morphology applies morphological operation to every channel, which is the grayscale morphology,
+// with binary mode the image is binarized by Otsu's threshold before the operation
+// value format: <operation>[:element=<square|cross|disk|rows>,radius=<n>,iterations=<n>,mode=<gray|binary>]
+// custom element is written as rows of 0 and 1 separated by dash, e.g. 010-111-010
+// see (https://en.wikipedia.org/wiki/Mathematical_morphology)
+func (b *bmp) Morphology(flagValue string) error {
+	operation, rest := flagValue, ""
+	for idx := range flagValue {
+		if flagValue[idx] == ':' {
+			operation, rest = flagValue[:idx], flagValue[idx+1:]
+			break
+		}
+	}
+
+	elementName, radius, iterations, mode := morphologyElement, morphologyRadius, morphologyIterations, "gray"
+	if rest != "" {
+		options, ok := utils.ParseOptions(rest)
+		if !ok {
+			return ErrIncorrectMorphologyValue
+		}
+		for key, value := range options {
+			switch key {
+			case "element":
+				elementName = value
+			case "radius":
+				radius, ok = utils.Atoi(value)
+				if !ok || radius < 1 {
+					return ErrIncorrectMorphologyValue
+				}
+			case "iterations":
+				iterations, ok = utils.Atoi(value)
+				if !ok || iterations < 1 {
+					return ErrIncorrectMorphologyValue
+				}
+			case "mode":
+				if value != "gray" && value != "binary" {
+					return ErrIncorrectMorphologyValue
+				}
+				mode = value
+			default:
+				return ErrIncorrectMorphologyValue
+			}
+		}
+	}
+
+	// Elements with radius above the sum of image sides cover the whole image from any pixel,
+	// so larger radii give the same result
+	radius = min(radius, int(b.dibHeader.Width)+len(b.pixelArray))
+	element, err := newStructElement(elementName, radius)
+	if err != nil {
+		return err
+	}
+
+	if mode == "binary" {
+		if err := b.Threshold([]string{"otsu"}); err != nil {
+			return err
+		}
+	}
+
+	// Iterations stop once the image no longer changes
+	repeat := func(dilation bool) {
+		for iteration := 0; iteration < iterations; iteration++ {
+			previous := b.pixelArray
+			b.pixelArray = b.morph(element, dilation)
+			if samePixels(previous, b.pixelArray) {
+				break
+			}
+		}
+	}
+	erode := func() {
+		repeat(false)
+	}
+	dilate := func() {
+		repeat(true)
+	}
+
+	switch operation {
+	case "erode":
+		erode()
+	case "dilate":
+		dilate()
+	case "open":
+		erode()
+		dilate()
+	case "close":
+		dilate()
+		erode()
+	case "gradient":
+		// Difference of dilation and erosion outlines the objects
+		source := b.copyPixelArray()
+		dilate()
+		dilated := b.pixelArray
+		b.pixelArray = source
+		erode()
+		b.subtract(dilated, b.pixelArray)
+	case "tophat":
+		// Difference of image and its opening keeps bright details smaller than element
+		source := b.copyPixelArray()
+		erode()
+		dilate()
+		b.subtract(source, b.pixelArray)
+	case "blackhat":
+		// Difference of closing and image keeps dark details smaller than element
+		source := b.copyPixelArray()
+		dilate()
+		erode()
+		b.subtract(b.pixelArray, source)
+	default:
+		return ErrIncorrectMorphologyValue
+	}
+
+	return nil
+}
+
+// newStructElement returns the structuring element by its name or custom rows of 0 and 1
+func newStructElement(name string, radius int) (structElement, error) {
+	element := structElement{radius: radius}
+
+	switch name {
+	case "square", "cross", "disk":
+		for dy := -radius; dy <= radius; dy++ {
+			for dx := -radius; dx <= radius; dx++ {
+				if name == "cross" && dx != 0 && dy != 0 || name == "disk" && dx*dx+dy*dy > radius*radius {
+					continue
+				}
+				element.offsets = append(element.offsets, [2]int{dx, dy})
+			}
+		}
+		element.square = name == "square"
+	default:
+		rows := utils.Split(name, "-")
+		height, width := len(rows), len(rows[0])
+		// Origin is in the center, so sizes must be odd
+		if height%2 == 0 || width%2 == 0 {
+			return element, ErrIncorrectStructElement
+		}
+		for y, row := range rows {
+			if len(row) != width {
+				return element, ErrIncorrectStructElement
+			}
+			for x := range row {
+				if row[x] == '1' {
+					element.offsets = append(element.offsets, [2]int{x - width/2, y - height/2})
+				} else if row[x] != '0' {
+					return element, ErrIncorrectStructElement
+				}
+			}
+		}
+		if len(element.offsets) == 0 {
+			return element, ErrIncorrectStructElement
+		}
+		element.radius = max(width/2, height/2)
+	}
+
+	return element, nil
+}
+
+// morph returns the pixel array where every channel value is the minimum (erosion)
+// or the maximum (dilation) of values under the structuring element
+func (b *bmp) morph(element structElement, dilation bool) [][]byte {
+	if element.square {
+		// Square is a product of horizontal and vertical lines
+		horizontal, vertical := structElement{}, structElement{}
+		for offset := -element.radius; offset <= element.radius; offset++ {
+			horizontal.offsets = append(horizontal.offsets, [2]int{offset, 0})
+			vertical.offsets = append(vertical.offsets, [2]int{0, offset})
+		}
+		b.pixelArray = b.morph(horizontal, dilation)
+		return b.morph(vertical, dilation)
+	}
+
+	result := make([][]byte, len(b.pixelArray))
+	height, width := len(b.pixelArray), int(b.dibHeader.Width)
+
+	for rowIdx := range result {
+		result[rowIdx] = make([]byte, len(b.pixelArray[rowIdx]))
+		for colIdx := 0; colIdx < width; colIdx++ {
+			extremes := [3]byte{255, 255, 255}
+			if dilation {
+				extremes = [3]byte{}
+			}
+
+			for _, offset := range element.offsets {
+				// Rows of pixel array go bottom-up, so vertical offset is inverted
+				y, x := rowIdx-offset[1], colIdx+offset[0]
+				// Pixels outside of image don't affect the result
+				if y < 0 || y >= height || x < 0 || x >= width {
+					continue
+				}
+				for channel := range extremes {
+					value := b.pixelArray[y][x*3+channel]
+					if dilation && value > extremes[channel] || !dilation && value < extremes[channel] {
+						extremes[channel] = value
+					}
+				}
+			}
+
+			copy(result[rowIdx][colIdx*3:colIdx*3+3], extremes[:])
+		}
+	}
+
+	return result
+}
+
+// samePixels reports whether pixel arrays are equal
+func samePixels(first, second [][]byte) bool {
+	for rowIdx := range first {
+		if string(first[rowIdx]) != string(second[rowIdx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// subtract assigns the saturated difference of minuend and subtrahend to the pixel array
+func (b *bmp) subtract(minuend, subtrahend [][]byte) {
+	result := make([][]byte, len(b.pixelArray))
+	for rowIdx := range result {
+		result[rowIdx] = make([]byte, len(b.pixelArray[rowIdx]))
+		for colIdx := uint32(0); colIdx < b.dibHeader.Width*3; colIdx++ {
+			if minuend[rowIdx][colIdx] > subtrahend[rowIdx][colIdx] {
+				result[rowIdx][colIdx] = minuend[rowIdx][colIdx] - subtrahend[rowIdx][colIdx]
+			}
+		}
+	}
+	b.pixelArray = result
+}
@@ -0,0 +1,186 @@
+package bmp
+
+import "testing"
+
+// newMorphologyBmp returns black image with white pixels at the points, points go from the top of image
+func newMorphologyBmp(width, height int, points [][2]int) *bmp {
+	testBmp := newTestBmp(width, height, [3]byte{})
+	for _, point := range points {
+		copy(testBmp.row(point[1])[point[0]*3:], []byte{255, 255, 255})
+	}
+	return testBmp
+}
+
+// squarePoints returns the points of square with the top left corner and the side
+func squarePoints(x, y, side int) [][2]int {
+	points := [][2]int{}
+	for dy := 0; dy < side; dy++ {
+		for dx := 0; dx < side; dx++ {
+			points = append(points, [2]int{x + dx, y + dy})
+		}
+	}
+	return points
+}
+
+func TestMorphologyElements(t *testing.T) {
+	type testData struct {
+		element string
+		// Pixels of single pixel dilated by the element of radius 2
+		want int
+	}
+
+	tests := []testData{
+		{element: "square", want: 25},
+		{element: "cross", want: 9},
+		{element: "disk", want: 13},
+		// Diagonal cross, radius is taken from the rows
+		{element: "10001-01010-00100-01010-10001", want: 9},
+	}
+
+	for _, test := range tests {
+		t.Run(test.element, func(t *testing.T) {
+			element, err := newStructElement(test.element, 2)
+			if err != nil {
+				t.Fatalf("newStructElement() error = %v", err)
+			}
+
+			testBmp := newMorphologyBmp(9, 9, [][2]int{{4, 4}})
+			if err := testBmp.Morphology("dilate:radius=2,element=" + test.element); err != nil {
+				t.Fatalf("Morphology() error = %v", err)
+			}
+			lit := litPixels(testBmp)
+			if len(lit) != test.want {
+				t.Errorf("dilate lit %d pixels, want %d", len(lit), test.want)
+			}
+			for _, offset := range element.offsets {
+				if lit[[2]int{4 + offset[0], 4 + offset[1]}] != 255 {
+					t.Errorf("dilate didn't light the pixel with offset %v", offset)
+				}
+			}
+
+			// Erosion of the dilated pixel by the same element leaves only the pixel
+			if err := testBmp.Morphology("erode:radius=2,element=" + test.element); err != nil {
+				t.Fatalf("Morphology() error = %v", err)
+			}
+			if lit := litPixels(testBmp); len(lit) != 1 || lit[[2]int{4, 4}] != 255 {
+				t.Errorf("erode lit %v, want only the center", lit)
+			}
+		})
+	}
+
+	for _, name := range []string{"0110-1001", "010-11-010", "000-000-000", "010-121-010"} {
+		if _, err := newStructElement(name, 1); err != ErrIncorrectStructElement {
+			t.Errorf("newStructElement(%s) error = %v, want %v", name, err, ErrIncorrectStructElement)
+		}
+	}
+}
+
+func TestMorphologyOperations(t *testing.T) {
+	// Block 5x5 with specks of a single pixel and 2x2 square, specks are smaller than the square element 3x3
+	block := squarePoints(2, 2, 5)
+	specks := append([][2]int{{10, 1}, {1, 10}}, squarePoints(9, 9, 2)...)
+
+	type testData struct {
+		name      string
+		value     string
+		points    [][2]int
+		want      [][2]int
+		wantCount int
+	}
+
+	tests := []testData{
+		{name: "Open removes specks", value: "open", points: append(append([][2]int{}, block...), specks...), want: block},
+		{name: "Tophat keeps specks", value: "tophat", points: append(append([][2]int{}, block...), specks...), want: specks},
+		{name: "Close keeps block", value: "close", points: block, want: block},
+		// Ring between the dilated block 7x7 and the eroded block 3x3
+		{name: "Gradient outlines block", value: "gradient", points: block, wantCount: 49 - 9},
+		{name: "Erode by two iterations", value: "erode:iterations=2", points: block, want: [][2]int{{4, 4}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testBmp := newMorphologyBmp(12, 12, test.points)
+			if err := testBmp.Morphology(test.value); err != nil {
+				t.Fatalf("Morphology() error = %v", err)
+			}
+			lit := litPixels(testBmp)
+			if test.want == nil {
+				if len(lit) != test.wantCount {
+					t.Errorf("Morphology() lit %d pixels, want %d", len(lit), test.wantCount)
+				}
+				return
+			}
+			if len(lit) != len(test.want) {
+				t.Errorf("Morphology() lit %d pixels, want %d", len(lit), len(test.want))
+			}
+			for _, point := range test.want {
+				if lit[point] != 255 {
+					t.Errorf("Morphology() pixel %v = %d, want 255", point, lit[point])
+				}
+			}
+		})
+	}
+}
+
+func TestMorphologyBlackhat(t *testing.T) {
+	// Dark hole of white image is filled by closing, so blackhat keeps only the hole
+	testBmp := newTestBmp(7, 7, [3]byte{255, 255, 255})
+	copy(testBmp.row(3)[3*3:], []byte{0, 0, 0})
+	if err := testBmp.Morphology("blackhat:element=cross"); err != nil {
+		t.Fatalf("Morphology() error = %v", err)
+	}
+	if lit := litPixels(testBmp); len(lit) != 1 || lit[[2]int{3, 3}] != 255 {
+		t.Errorf("Morphology() lit %v, want only the hole", lit)
+	}
+}
+
+func TestMorphologyBinary(t *testing.T) {
+	// Dark and light gray halves are binarized before dilation
+	testBmp := newTestBmp(8, 4, [3]byte{40, 40, 40})
+	for y := 0; y < 4; y++ {
+		for x := 4; x < 8; x++ {
+			copy(testBmp.row(y)[x*3:], []byte{200, 200, 200})
+		}
+	}
+	if err := testBmp.Morphology("dilate:mode=binary"); err != nil {
+		t.Fatalf("Morphology() error = %v", err)
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 8; x++ {
+			want := byte(0)
+			if x >= 3 {
+				want = 255
+			}
+			if value := testBmp.row(y)[x*3]; value != want {
+				t.Fatalf("Morphology() pixel (%d, %d) = %d, want %d", x, y, value, want)
+			}
+		}
+	}
+}
+
+func TestMorphologyLimits(t *testing.T) {
+	// Huge radius covers the whole image as the sum of sides, dilation fills the image with the only white pixel
+	for _, element := range []string{"square", "cross", "disk"} {
+		testBmp := newMorphologyBmp(6, 4, [][2]int{{1, 1}})
+		if err := testBmp.Morphology("dilate:radius=1000000000000,element=" + element); err != nil {
+			t.Fatalf("Morphology() error = %v", err)
+		}
+		want := 6 * 4
+		if element == "cross" {
+			// Row and column of the pixel
+			want = 6 + 4 - 1
+		}
+		if lit := litPixels(testBmp); len(lit) != want {
+			t.Errorf("Morphology() with %s lit %d pixels, want %d", element, len(lit), want)
+		}
+	}
+
+	// Iterations stop once the image is fully eroded
+	testBmp := newMorphologyBmp(6, 4, squarePoints(1, 1, 3))
+	if err := testBmp.Morphology("erode:iterations=100000000000"); err != nil {
+		t.Fatalf("Morphology() error = %v", err)
+	}
+	if lit := litPixels(testBmp); len(lit) != 0 {
+		t.Errorf("Morphology() lit %d pixels, want 0", len(lit))
+	}
+}
@@ -0,0 +1,162 @@
+package bmp
+
+import (
+	"errors"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectColorMatrix   = errors.New("Incorrect color matrix provided, 20 values or preset name expected")
+	ErrIncorrectChannelsValue = errors.New("Incorrect channels value provided")
+)
+
+// Color matrix 4x5 transforms RGBA color with offset:
+// [R' G' B' A'] = M * [R G B A 1], offsets are in range of color values [0, 255]
+// alpha of 24 bit image is always 255
+// see (https://developer.android.com/reference/android/graphics/ColorMatrix)
+type colorMatrix [4][5]float64
+
+// Builtin color matrices
+var colorMatrixPresets = map[string]colorMatrix{
+	"identity": {
+		{1, 0, 0, 0, 0},
+		{0, 1, 0, 0, 0},
+		{0, 0, 1, 0, 0},
+		{0, 0, 0, 1, 0},
+	},
+	// Channel filters retain only one channel
+	"red": {
+		{1, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0},
+		{0, 0, 0, 1, 0},
+	},
+	"green": {
+		{0, 0, 0, 0, 0},
+		{0, 1, 0, 0, 0},
+		{0, 0, 0, 0, 0},
+		{0, 0, 0, 1, 0},
+	},
+	"blue": {
+		{0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0},
+		{0, 0, 1, 0, 0},
+		{0, 0, 0, 1, 0},
+	},
+	// Microsoft recommended values
+	// see (https://idmnyu.github.io/p5.js-image/Filters/index.html)
+	"sepia": {
+		{.393, .769, .189, 0, 0},
+		{.349, .686, .168, 0, 0},
+		{.272, .534, .131, 0, 0},
+		{0, 0, 0, 1, 0},
+	},
+	"negative": {
+		{-1, 0, 0, 0, 255},
+		{0, -1, 0, 0, 255},
+		{0, 0, -1, 0, 255},
+		{0, 0, 0, 1, 0},
+	},
+	"grayscale": {
+		{.299, .587, .114, 0, 0},
+		{.299, .587, .114, 0, 0},
+		{.299, .587, .114, 0, 0},
+		{0, 0, 0, 1, 0},
+	},
+	"polaroid": {
+		{1.438, -.062, -.062, 0, -5},
+		{-.122, 1.378, -.122, 0, -5},
+		{-.016, -.016, 1.483, 0, -5},
+		{0, 0, 0, 1, 0},
+	},
+	"kodachrome": {
+		{1.129, -.397, -.040, 0, 63.7},
+		{-.164, 1.084, -.054, 0, 24.7},
+		{-.168, -.560, 1.601, 0, 35.6},
+		{0, 0, 0, 1, 0},
+	},
+	"vintage": {
+		{.628, .320, -.040, 0, 9.65},
+		{.026, .644, .033, 0, 7.46},
+		{.047, -.085, .524, 0, 5.16},
+		{0, 0, 0, 1, 0},
+	},
+}
+
+// ColorMatrix transforms colors of every pixel by the color matrix
+// value format: <preset name> or 20 comma separated values of matrix rows
+func (b *bmp) ColorMatrix(flagValue string) error {
+	if matrix, ok := colorMatrixPresets[flagValue]; ok {
+		b.applyColorMatrix(matrix)
+		return nil
+	}
+
+	values := utils.Split(flagValue, ",")
+	if len(values) != 20 {
+		return ErrIncorrectColorMatrix
+	}
+
+	var matrix colorMatrix
+	for idx, value := range values {
+		number, ok := utils.ParseFloat(value)
+		if !ok {
+			return ErrIncorrectColorMatrix
+		}
+		matrix[idx/5][idx%5] = number
+	}
+
+	b.applyColorMatrix(matrix)
+	return nil
+}
+
+// Channels swaps or extracts channels, every letter sets the source of red, green and blue channels
+// value format: 3 letters of r, g, b, 0 (black) or 1 (full value), e.g. bgr, rrr, 0g0
+func (b *bmp) Channels(flagValue string) error {
+	if len(flagValue) != 3 {
+		return ErrIncorrectChannelsValue
+	}
+
+	matrix := colorMatrixPresets["identity"]
+	for channel := 0; channel < 3; channel++ {
+		matrix[channel] = [5]float64{}
+		switch flagValue[channel] {
+		case 'r':
+			matrix[channel][0] = 1
+		case 'g':
+			matrix[channel][1] = 1
+		case 'b':
+			matrix[channel][2] = 1
+		case '0':
+		case '1':
+			matrix[channel][4] = 255
+		default:
+			return ErrIncorrectChannelsValue
+		}
+	}
+
+	b.applyColorMatrix(matrix)
+	return nil
+}
+
+// applyColorMatrix transforms colors of every pixel by the matrix, results are clamped to [0, 255]
+func (b *bmp) applyColorMatrix(matrix colorMatrix) {
+	for rowIdx := range b.pixelArray {
+		for colIdx := uint32(0); colIdx < b.dibHeader.Width*3; colIdx += 3 {
+			pixel := b.pixelArray[rowIdx][colIdx : colIdx+3]
+			// RGBA order of matrix columns
+			color := [4]float64{float64(pixel[2]), float64(pixel[1]), float64(pixel[0]), 255}
+
+			var result [3]float64
+			for channel := range result {
+				row := matrix[channel]
+				result[channel] = row[0]*color[0] + row[1]*color[1] + row[2]*color[2] + row[3]*color[3] + row[4]
+			}
+
+			pixel[0] = clampByte(result[2]) // Blue
+			pixel[1] = clampByte(result[1]) // Green
+			pixel[2] = clampByte(result[0]) // Red
+		}
+	}
+}
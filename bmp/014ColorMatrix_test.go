@@ -0,0 +1,82 @@
+package bmp
+
+import "testing"
+
+func TestColorMatrix(t *testing.T) {
+	type testData struct {
+		name  string
+		value string
+		// Pixels are in BGR order
+		pixel [3]byte
+		want  [3]byte
+		err   error
+	}
+
+	tests := []testData{
+		{name: "Red preset", value: "red", pixel: [3]byte{200, 150, 100}, want: [3]byte{0, 0, 100}},
+		{name: "Green preset", value: "green", pixel: [3]byte{200, 150, 100}, want: [3]byte{0, 150, 0}},
+		{name: "Blue preset", value: "blue", pixel: [3]byte{200, 150, 100}, want: [3]byte{200, 0, 0}},
+		{name: "Negative preset", value: "negative", pixel: [3]byte{200, 150, 100}, want: [3]byte{55, 105, 155}},
+		// Sepia values are rounded, green 145.73 becomes 146
+		{name: "Sepia preset", value: "sepia", pixel: [3]byte{30, 200, 10}, want: [3]byte{113, 146, 163}},
+		{name: "Sepia preset clamps values", value: "sepia", pixel: [3]byte{255, 255, 255}, want: [3]byte{239, 255, 255}},
+		{name: "Grayscale preset", value: "grayscale", pixel: [3]byte{200, 150, 100}, want: [3]byte{141, 141, 141}},
+		{name: "Custom matrix with offset", value: "0.5,0,0,0,10,0,0,1,0,0,0,1,0,0,0,0,0,0,1,0", pixel: [3]byte{200, 150, 100}, want: [3]byte{150, 200, 60}},
+		{name: "Matrix of 3 values", value: "1,2,3", err: ErrIncorrectColorMatrix},
+		{name: "Matrix with letter", value: "1,0,0,0,0,0,1,0,0,0,0,0,1,0,0,0,0,0,x,0", err: ErrIncorrectColorMatrix},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testBmp := newTestBmp(2, 2, test.pixel)
+			if err := testBmp.ColorMatrix(test.value); err != test.err {
+				t.Fatalf("ColorMatrix() error = %v, want %v", err, test.err)
+			}
+			if test.err != nil {
+				return
+			}
+			for _, row := range testBmp.pixelArray {
+				if [3]byte(row[:3]) != test.want {
+					t.Fatalf("ColorMatrix() pixel = %v, want %v", row[:3], test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestChannels(t *testing.T) {
+	type testData struct {
+		value string
+		want  [3]byte
+		err   error
+	}
+
+	// Source pixel is red 100, green 150 and blue 200
+	tests := []testData{
+		{value: "rgb", want: [3]byte{200, 150, 100}},
+		{value: "bgr", want: [3]byte{100, 150, 200}},
+		{value: "rrr", want: [3]byte{100, 100, 100}},
+		{value: "gbr", want: [3]byte{100, 200, 150}},
+		{value: "10g", want: [3]byte{150, 0, 255}},
+		{value: "aaa", err: ErrIncorrectChannelsValue},
+		{value: "rgx", err: ErrIncorrectChannelsValue},
+		{value: "rgba", err: ErrIncorrectChannelsValue},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			testBmp := newTestBmp(2, 2, [3]byte{200, 150, 100})
+			if err := testBmp.Channels(test.value); err != test.err {
+				t.Fatalf("Channels() error = %v, want %v", err, test.err)
+			}
+			if test.err != nil {
+				return
+			}
+			for _, row := range testBmp.pixelArray {
+				if [3]byte(row[:3]) != test.want {
+					t.Fatalf("Channels() pixel = %v, want %v", row[:3], test.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package bmp
+
+import (
+	"errors"
+	"os"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectCubeFile      = errors.New("LUT file is not in .cube format, or file is corrupted")
+	ErrIncorrectInterpolation = errors.New("Incorrect LUT interpolation method provided")
+)
+
+// Lookup table of Adobe/Resolve .cube file, colors are in RGB order with values in range of domain
+// see Adobe Cube LUT Specification 1.0
+type cubeLut struct {
+	size1D int
+	size3D int
+	// Red changes the fastest, then green, then blue
+	table1D   [][3]float64
+	table3D   [][3]float64
+	domainMin [3]float64
+	domainMax [3]float64
+}
+
+// Lut applies the .cube lookup table to every pixel
+// value format: <file>[:<trilinear|tetrahedral>], tetrahedral interpolation is used by default
+func (b *bmp) Lut(flagValue string) error {
+	fileName, interpolation := flagValue, "tetrahedral"
+	for idx := len(flagValue) - 1; idx >= 0; idx-- {
+		if flagValue[idx] == ':' {
+			if method := flagValue[idx+1:]; method == "trilinear" || method == "tetrahedral" {
+				fileName, interpolation = flagValue[:idx], method
+			}
+			break
+		}
+	}
+
+	lut, err := loadCube(fileName)
+	if err != nil {
+		return err
+	}
+
+	// Colors of 24 bit image are limited, so results are cached
+	cache := make(map[[3]byte][3]byte)
+	for rowIdx := range b.pixelArray {
+		for colIdx := uint32(0); colIdx < b.dibHeader.Width*3; colIdx += 3 {
+			pixel := b.pixelArray[rowIdx][colIdx : colIdx+3]
+			key := [3]byte{pixel[0], pixel[1], pixel[2]}
+
+			result, ok := cache[key]
+			if !ok {
+				result, err = lut.apply(pixel[0], pixel[1], pixel[2], interpolation)
+				if err != nil {
+					return err
+				}
+				cache[key] = result
+			}
+			copy(pixel, result[:])
+		}
+	}
+
+	return nil
+}
+
+// loadCube parses the .cube file with 1D and/or 3D lookup table
+func loadCube(fileName string) (*cubeLut, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	lut := &cubeLut{domainMax: [3]float64{1, 1, 1}}
+	for _, line := range utils.Split(string(content), "\n") {
+		fields := utils.Fields(line)
+		// Empty lines and comments are skipped
+		if len(fields) == 0 || fields[0][0] == '#' {
+			continue
+		}
+
+		switch fields[0] {
+		case "TITLE":
+		case "LUT_1D_SIZE", "LUT_3D_SIZE":
+			if len(fields) != 2 {
+				return nil, ErrIncorrectCubeFile
+			}
+			size, ok := utils.Atoi(fields[1])
+			if !ok || size < 2 || fields[0] == "LUT_1D_SIZE" && size > 65536 || fields[0] == "LUT_3D_SIZE" && size > 256 {
+				return nil, ErrIncorrectCubeFile
+			}
+			if fields[0] == "LUT_1D_SIZE" {
+				lut.size1D = size
+			} else {
+				lut.size3D = size
+			}
+		case "DOMAIN_MIN", "DOMAIN_MAX", "LUT_1D_INPUT_RANGE", "LUT_3D_INPUT_RANGE":
+			values, err := parseCubeValues(fields[1:], fields[0] == "DOMAIN_MIN" || fields[0] == "DOMAIN_MAX")
+			if err != nil {
+				return nil, err
+			}
+			switch fields[0] {
+			case "DOMAIN_MIN":
+				lut.domainMin = values
+			case "DOMAIN_MAX":
+				lut.domainMax = values
+			default:
+				// Input range of Resolve format sets the same domain for all channels
+				lut.domainMin = [3]float64{values[0], values[0], values[0]}
+				lut.domainMax = [3]float64{values[1], values[1], values[1]}
+			}
+		default:
+			values, err := parseCubeValues(fields, true)
+			if err != nil {
+				return nil, err
+			}
+			// Table of 1D LUT goes first when file contains both
+			if len(lut.table1D) < lut.size1D {
+				lut.table1D = append(lut.table1D, values)
+			} else {
+				lut.table3D = append(lut.table3D, values)
+			}
+		}
+	}
+
+	if lut.size1D == 0 && lut.size3D == 0 ||
+		len(lut.table1D) != lut.size1D || len(lut.table3D) != lut.size3D*lut.size3D*lut.size3D {
+		return nil, ErrIncorrectCubeFile
+	}
+	for channel := 0; channel < 3; channel++ {
+		if lut.domainMax[channel] <= lut.domainMin[channel] {
+			return nil, ErrIncorrectCubeFile
+		}
+	}
+
+	return lut, nil
+}
+
+// parseCubeValues parses 3 numbers of table row or domain, input range has 2 numbers
+func parseCubeValues(fields []string, triple bool) ([3]float64, error) {
+	var values [3]float64
+	if triple && len(fields) != 3 || !triple && len(fields) != 2 {
+		return values, ErrIncorrectCubeFile
+	}
+	for idx, field := range fields {
+		value, ok := utils.ParseFloat(field)
+		if !ok {
+			return values, ErrIncorrectCubeFile
+		}
+		values[idx] = value
+	}
+	return values, nil
+}
+
+// apply returns the color transformed by the lookup table, colors go in BGR order
+func (l *cubeLut) apply(blue, green, red byte, interpolation string) ([3]byte, error) {
+	// Input is normalized to range [0, 1] of domain
+	var color [3]float64
+	for channel, value := range [3]byte{red, green, blue} {
+		input := float64(value) / 255
+		color[channel] = min(max((input-l.domainMin[channel])/(l.domainMax[channel]-l.domainMin[channel]), 0), 1)
+	}
+
+	if l.size1D > 0 {
+		for channel := range color {
+			position := color[channel] * float64(l.size1D-1)
+			low := min(int(position), l.size1D-2)
+			fraction := position - float64(low)
+			output := l.table1D[low][channel]*(1-fraction) + l.table1D[low+1][channel]*fraction
+			color[channel] = min(max(output, 0), 1)
+		}
+	}
+
+	if l.size3D > 0 {
+		var err error
+		if color, err = l.lookup3D(color, interpolation); err != nil {
+			return [3]byte{}, err
+		}
+	}
+
+	return [3]byte{clampByte(color[2] * 255), clampByte(color[1] * 255), clampByte(color[0] * 255)}, nil
+}
+
+// lookup3D interpolates the normalized RGB color between the nodes of 3D table
+// see (https://en.wikipedia.org/wiki/Trilinear_interpolation)
+func (l *cubeLut) lookup3D(color [3]float64, interpolation string) ([3]float64, error) {
+	var low [3]int
+	var fraction [3]float64
+	for channel := range color {
+		position := color[channel] * float64(l.size3D-1)
+		low[channel] = min(int(position), l.size3D-2)
+		fraction[channel] = position - float64(low[channel])
+	}
+
+	// corner returns the table node with red, green and blue offsets of 0 or 1 from the low node
+	corner := func(red, green, blue int) [3]float64 {
+		return l.table3D[(low[0]+red)+(low[1]+green)*l.size3D+(low[2]+blue)*l.size3D*l.size3D]
+	}
+	// mix returns the weighted sum of nodes
+	mix := func(weights []float64, nodes ...[3]float64) [3]float64 {
+		var result [3]float64
+		for idx, node := range nodes {
+			for channel := range result {
+				result[channel] += weights[idx] * node[channel]
+			}
+		}
+		return result
+	}
+
+	fr, fg, fb := fraction[0], fraction[1], fraction[2]
+	switch interpolation {
+	case "trilinear":
+		return mix(
+			[]float64{
+				(1 - fr) * (1 - fg) * (1 - fb), fr * (1 - fg) * (1 - fb), (1 - fr) * fg * (1 - fb), fr * fg * (1 - fb),
+				(1 - fr) * (1 - fg) * fb, fr * (1 - fg) * fb, (1 - fr) * fg * fb, fr * fg * fb,
+			},
+			corner(0, 0, 0), corner(1, 0, 0), corner(0, 1, 0), corner(1, 1, 0),
+			corner(0, 0, 1), corner(1, 0, 1), corner(0, 1, 1), corner(1, 1, 1),
+		), nil
+	case "tetrahedral":
+		// Cube is split into 6 tetrahedra by the order of fractions, every one shares the main diagonal
+		c000, c111 := corner(0, 0, 0), corner(1, 1, 1)
+		switch {
+		case fr > fg && fg > fb:
+			return mix([]float64{1 - fr, fr - fg, fg - fb, fb}, c000, corner(1, 0, 0), corner(1, 1, 0), c111), nil
+		case fr > fb && fb >= fg:
+			return mix([]float64{1 - fr, fr - fb, fb - fg, fg}, c000, corner(1, 0, 0), corner(1, 0, 1), c111), nil
+		case fb >= fr && fr > fg:
+			return mix([]float64{1 - fb, fb - fr, fr - fg, fg}, c000, corner(0, 0, 1), corner(1, 0, 1), c111), nil
+		case fb > fg && fg >= fr:
+			return mix([]float64{1 - fb, fb - fg, fg - fr, fr}, c000, corner(0, 0, 1), corner(0, 1, 1), c111), nil
+		case fg >= fb && fb > fr:
+			return mix([]float64{1 - fg, fg - fb, fb - fr, fr}, c000, corner(0, 1, 0), corner(0, 1, 1), c111), nil
+		default:
+			return mix([]float64{1 - fg, fg - fr, fr - fb, fb}, c000, corner(0, 1, 0), corner(1, 1, 0), c111), nil
+		}
+	default:
+		return [3]float64{}, ErrIncorrectInterpolation
+	}
+}
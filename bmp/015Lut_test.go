@@ -0,0 +1,80 @@
+package bmp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCubeLut(t *testing.T) {
+	type testData struct {
+		name          string
+		content       string
+		interpolation string
+		input         [3]byte // BGR
+		output        [3]byte
+		err           error
+	}
+
+	identity3D := "TITLE \"identity\"\nLUT_3D_SIZE 2\n0 0 0\n1 0 0\n0 1 0\n1 1 0\n0 0 1\n1 0 1\n0 1 1\n1 1 1\n"
+
+	tests := []testData{
+		{
+			name:          "Identity 3D LUT with tetrahedral interpolation",
+			content:       identity3D,
+			interpolation: "tetrahedral",
+			input:         [3]byte{10, 128, 250},
+			output:        [3]byte{10, 128, 250},
+		},
+		{
+			name:          "Identity 3D LUT with trilinear interpolation",
+			content:       identity3D,
+			interpolation: "trilinear",
+			input:         [3]byte{200, 3, 77},
+			output:        [3]byte{200, 3, 77},
+		},
+		{
+			name:          "Inverting 1D LUT with exponent values",
+			content:       "# comment\nLUT_1D_SIZE 2\n1.0e+0 1.0E0 1\n0 0 0\n",
+			interpolation: "tetrahedral",
+			input:         [3]byte{0, 55, 255},
+			output:        [3]byte{255, 200, 0},
+		},
+		{
+			name:          "Domain of 3D LUT",
+			content:       "LUT_3D_SIZE 2\nDOMAIN_MIN 0 0 0\nDOMAIN_MAX 0.5 0.5 0.5\n0 0 0\n1 0 0\n0 1 0\n1 1 0\n0 0 1\n1 0 1\n0 1 1\n1 1 1\n",
+			interpolation: "trilinear",
+			input:         [3]byte{51, 0, 255},
+			output:        [3]byte{102, 0, 255},
+		},
+		{
+			name:    "Incomplete 3D table",
+			content: "LUT_3D_SIZE 2\n0 0 0\n1 0 0\n",
+			err:     ErrIncorrectCubeFile,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "look.cube")
+			if err := os.WriteFile(fileName, []byte(test.content), 0o644); err != nil {
+				t.Fatalf("Error while writing %s: %s\n", fileName, err)
+			}
+
+			lut, err := loadCube(fileName)
+			if err != test.err {
+				t.Fatalf("loadCube() error = %v, wantErr %v", err, test.err)
+			} else if err != nil {
+				return
+			}
+
+			output, err := lut.apply(test.input[0], test.input[1], test.input[2], test.interpolation)
+			if err != nil {
+				t.Fatalf("apply() error = %v", err)
+			}
+			if output != test.output {
+				t.Errorf("apply() = %v, want %v", output, test.output)
+			}
+		})
+	}
+}
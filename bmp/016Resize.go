@@ -0,0 +1,338 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectResizeValue  = errors.New("Incorrect resize value provided")
+	ErrIncorrectResizeFilter = errors.New("Incorrect resampling filter provided")
+	ErrIncorrectResizeMode   = errors.New("Incorrect resize mode provided")
+	ErrIncorrectColorValue   = errors.New("Incorrect color provided, format: rrggbb")
+	ErrImageTooLarge         = errors.New("Image is too large, width*height can't exceed 67108864 pixels")
+)
+
+// Limit of pixels of resulting image, 8192x8192, keeps memory of pixel array and resampling buffers sane
+const maxPixels = 1 << 26
+
+// Default parameters of resizing
+const (
+	resizeFilter = "bicubic"
+	resizeMode   = "stretch"
+)
+
+// Resampling filter is the kernel function with support radius in source pixels
+// see (https://en.wikipedia.org/wiki/Image_scaling)
+type resampleFilter struct {
+	support float64
+	kernel  func(x float64) float64
+}
+
+// Resampling filters, nearest neighbor is processed separately
+var resampleFilters = map[string]resampleFilter{
+	// Area averaging is the box filter stretched over all covered source pixels on downscale
+	"area": {0.5, func(x float64) float64 {
+		if x >= -0.5 && x < 0.5 {
+			return 1
+		}
+		return 0
+	}},
+	"bilinear": {1, func(x float64) float64 {
+		return max(1-math.Abs(x), 0)
+	}},
+	// Keys cubic convolution with a = -0.5
+	// see (https://en.wikipedia.org/wiki/Bicubic_interpolation#Bicubic_convolution_algorithm)
+	"bicubic": {2, func(x float64) float64 {
+		x = math.Abs(x)
+		if x < 1 {
+			return (1.5*x-2.5)*x*x + 1
+		} else if x < 2 {
+			return ((-0.5*x+2.5)*x-4)*x + 2
+		}
+		return 0
+	}},
+	// see (https://en.wikipedia.org/wiki/Lanczos_resampling)
+	"lanczos3": {3, func(x float64) float64 {
+		if x == 0 {
+			return 1
+		} else if x <= -3 || x >= 3 {
+			return 0
+		}
+		return 3 * math.Sin(math.Pi*x) * math.Sin(math.Pi*x/3) / (math.Pi * math.Pi * x * x)
+	}},
+}
+
+// Resize changes dimensions of the image
+// value format: <width>x<height>[:filter=<filter>,mode=<mode>,color=<rrggbb>,protect=<mask file>,remove=<mask file>]
+// zero width or height is calculated from the other one preserving aspect ratio
+// modes: stretch ignores aspect ratio, fit keeps image inside of the size,
+// letterbox fits image and fills the rest with color, fill covers the size and crops the rest,
+// seam is content-aware resize which removes or inserts the least noticeable seams, masks mark pixels to protect or remove
+func (b *bmp) Resize(flagValue string) error {
+	size, options, err := splitResizeValue(flagValue)
+	if err != nil {
+		return err
+	}
+
+	dimensions := utils.Split(size, "x")
+	if len(dimensions) != 2 {
+		return ErrIncorrectResizeValue
+	}
+	width, ok := utils.Atoi(dimensions[0])
+	if !ok || width < 0 {
+		return ErrIncorrectResizeValue
+	}
+	height, ok := utils.Atoi(dimensions[1])
+	if !ok || height < 0 || width == 0 && height == 0 {
+		return ErrIncorrectResizeValue
+	}
+	if err := checkSize(width, height); err != nil {
+		return err
+	}
+
+	filter, mode, protect, remove := resizeFilter, resizeMode, "", ""
+	var fill [3]byte
+	for key, value := range options {
+		switch key {
+		case "filter":
+			filter = value
+		case "mode":
+			mode = value
+		case "protect":
+			protect = value
+		case "remove":
+			remove = value
+		case "color":
+			red, green, blue, ok := utils.ParseHexColor(value)
+			if !ok {
+				return ErrIncorrectColorValue
+			}
+			fill = [3]byte{blue, green, red}
+		default:
+			return ErrIncorrectResizeValue
+		}
+	}
+
+	sourceWidth, sourceHeight := float64(b.dibHeader.Width), float64(len(b.pixelArray))
+	if width == 0 {
+		width = max(int(math.Round(sourceWidth*float64(height)/sourceHeight)), 1)
+	} else if height == 0 {
+		height = max(int(math.Round(sourceHeight*float64(width)/sourceWidth)), 1)
+	}
+
+	// Masks are applicable only to seam carving
+	if mode != "seam" && (protect != "" || remove != "") {
+		return ErrIncorrectResizeValue
+	}
+	if err := checkSize(width, height); err != nil {
+		return err
+	}
+
+	switch mode {
+	case "stretch":
+		return b.resample(width, height, filter)
+	case "seam":
+		return b.seamCarve(width, height, protect, remove)
+	case "fit", "letterbox", "fill":
+		scale := min(float64(width)/sourceWidth, float64(height)/sourceHeight)
+		if mode == "fill" {
+			scale = max(float64(width)/sourceWidth, float64(height)/sourceHeight)
+		}
+		scaledWidth := max(int(math.Round(sourceWidth*scale)), 1)
+		scaledHeight := max(int(math.Round(sourceHeight*scale)), 1)
+		if err := b.resample(scaledWidth, scaledHeight, filter); err != nil {
+			return err
+		}
+
+		switch mode {
+		case "letterbox":
+			b.placeOnCanvas(width, height, (width-scaledWidth)/2, (height-scaledHeight)/2, fill)
+		case "fill":
+			b.crop((scaledWidth-width)/2, (scaledHeight-height)/2, min(width, scaledWidth), min(height, scaledHeight))
+		}
+		return nil
+	default:
+		return ErrIncorrectResizeMode
+	}
+}
+
+// Scale changes dimensions of the image by the percent preserving aspect ratio
+// value format: <percent>%[:filter=<filter>]
+func (b *bmp) Scale(flagValue string) error {
+	percentValue, options, err := splitResizeValue(flagValue)
+	if err != nil {
+		return err
+	}
+	if len(percentValue) > 0 && percentValue[len(percentValue)-1] == '%' {
+		percentValue = percentValue[:len(percentValue)-1]
+	}
+	percent, ok := utils.ParseFloat(percentValue)
+	if !ok || percent <= 0 {
+		return ErrIncorrectResizeValue
+	}
+
+	filter := resizeFilter
+	for key, value := range options {
+		if key != "filter" {
+			return ErrIncorrectResizeValue
+		}
+		filter = value
+	}
+
+	// Sides are checked before conversion to int which doesn't fit huge values
+	width, height := math.Round(float64(b.dibHeader.Width)*percent/100), math.Round(float64(len(b.pixelArray))*percent/100)
+	if width*height > maxPixels {
+		return ErrImageTooLarge
+	}
+	return b.resample(max(int(width), 1), max(int(height), 1), filter)
+}
+
+// checkSize returns error if the image of width and height exceeds the limit of pixels,
+// sides are checked separately so that their product doesn't overflow
+func checkSize(width, height int) error {
+	if width > maxPixels || height > maxPixels || width*height > maxPixels {
+		return ErrImageTooLarge
+	}
+	return nil
+}
+
+// splitResizeValue splits the value to the size and options after colon
+func splitResizeValue(flagValue string) (string, map[string]string, error) {
+	for idx := range flagValue {
+		if flagValue[idx] == ':' {
+			options, ok := utils.ParseOptions(flagValue[idx+1:])
+			if !ok {
+				return "", nil, ErrIncorrectResizeValue
+			}
+			return flagValue[:idx], options, nil
+		}
+	}
+	return flagValue, nil, nil
+}
+
+// resample changes dimensions of the image by the filter,
+// filters are separable, so rows are resampled first and then columns
+func (b *bmp) resample(width, height int, filterName string) error {
+	if err := checkSize(width, height); err != nil {
+		return err
+	}
+	sourceWidth, sourceHeight := int(b.dibHeader.Width), len(b.pixelArray)
+	source := b.pixelArray
+
+	if filterName == "nearest" {
+		b.setSize(uint32(width), uint32(height))
+		for rowIdx := range b.pixelArray {
+			sourceRow := source[min(int((float64(rowIdx)+0.5)*float64(sourceHeight)/float64(height)), sourceHeight-1)]
+			for colIdx := 0; colIdx < width; colIdx++ {
+				sourceCol := min(int((float64(colIdx)+0.5)*float64(sourceWidth)/float64(width)), sourceWidth-1)
+				copy(b.pixelArray[rowIdx][colIdx*3:colIdx*3+3], sourceRow[sourceCol*3:sourceCol*3+3])
+			}
+		}
+		return nil
+	}
+
+	filter, ok := resampleFilters[filterName]
+	if !ok {
+		return ErrIncorrectResizeFilter
+	}
+
+	// Horizontal pass
+	columnWeights := resampleWeights(sourceWidth, width, filter)
+	horizontal := make([][]float32, sourceHeight)
+	for rowIdx := range horizontal {
+		horizontal[rowIdx] = make([]float32, width*3)
+		for colIdx, weights := range columnWeights {
+			for _, weight := range weights {
+				for channel := 0; channel < 3; channel++ {
+					horizontal[rowIdx][colIdx*3+channel] += float32(weight.weight) * float32(source[rowIdx][weight.idx*3+channel])
+				}
+			}
+		}
+	}
+
+	// Vertical pass
+	rowWeights := resampleWeights(sourceHeight, height, filter)
+	b.setSize(uint32(width), uint32(height))
+	for rowIdx, weights := range rowWeights {
+		for colIdx := 0; colIdx < width*3; colIdx++ {
+			sum := 0.
+			for _, weight := range weights {
+				sum += weight.weight * float64(horizontal[weight.idx][colIdx])
+			}
+			b.pixelArray[rowIdx][colIdx] = clampByte(sum)
+		}
+	}
+
+	return nil
+}
+
+// Contribution of source pixel to the resampled one
+type resampleWeight struct {
+	idx    int
+	weight float64
+}
+
+// resampleWeights returns normalized contributions of source pixels to every resampled pixel,
+// the kernel is stretched on downscale so that all source pixels contribute
+func resampleWeights(sourceSize, size int, filter resampleFilter) [][]resampleWeight {
+	scale := float64(sourceSize) / float64(size)
+	filterScale := max(scale, 1)
+	support := filter.support * filterScale
+
+	weights := make([][]resampleWeight, size)
+	for idx := range weights {
+		// Center of resampled pixel in source coordinates
+		center := (float64(idx)+0.5)*scale - 0.5
+		first := max(int(math.Ceil(center-support)), 0)
+		last := min(int(math.Floor(center+support)), sourceSize-1)
+
+		total := 0.
+		for sourceIdx := first; sourceIdx <= last; sourceIdx++ {
+			weight := filter.kernel((float64(sourceIdx) - center) / filterScale)
+			if weight != 0 {
+				weights[idx] = append(weights[idx], resampleWeight{sourceIdx, weight})
+				total += weight
+			}
+		}
+
+		// The nearest pixel is taken when kernel doesn't cover any pixel
+		if total == 0 {
+			weights[idx] = []resampleWeight{{min(max(int(math.Round(center)), 0), sourceSize-1), 1}}
+			continue
+		}
+		for weightIdx := range weights[idx] {
+			weights[idx][weightIdx].weight /= total
+		}
+	}
+
+	return weights
+}
+
+// placeOnCanvas puts the image on the blank canvas filled with color, top left corner of image is at x, y
+// parts of image outside of canvas are cut
+func (b *bmp) placeOnCanvas(width, height, x, y int, fill [3]byte) {
+	source := b.pixelArray
+	sourceWidth, sourceHeight := int(b.dibHeader.Width), len(source)
+	b.setSize(uint32(width), uint32(height))
+
+	for rowIdx := 0; rowIdx < height; rowIdx++ {
+		row := b.row(rowIdx)
+		for colIdx := 0; colIdx < width; colIdx++ {
+			copy(row[colIdx*3:colIdx*3+3], fill[:])
+		}
+
+		sourceRowIdx := rowIdx - y
+		if sourceRowIdx < 0 || sourceRowIdx >= sourceHeight {
+			continue
+		}
+		sourceRow := source[sourceHeight-1-sourceRowIdx]
+		left, right := max(x, 0), min(x+sourceWidth, width)
+		if left < right {
+			copy(row[left*3:right*3], sourceRow[(left-x)*3:(right-x)*3])
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package bmp
+
+import "testing"
+
+func TestResize(t *testing.T) {
+	type testData struct {
+		name       string
+		value      string
+		width      int
+		height     int
+		wantWidth  uint32
+		wantHeight uint32
+	}
+
+	tests := []testData{
+		{name: "Bicubic downscale", value: "7x5", width: 30, height: 20, wantWidth: 7, wantHeight: 5},
+		{name: "Lanczos upscale", value: "45x0:filter=lanczos3", width: 30, height: 20, wantWidth: 45, wantHeight: 30},
+		{name: "Area downscale", value: "0x3:filter=area", width: 30, height: 20, wantWidth: 5, wantHeight: 3},
+		{name: "Fit", value: "10x10:mode=fit,filter=bilinear", width: 30, height: 20, wantWidth: 10, wantHeight: 7},
+		{name: "Fill", value: "10x10:mode=fill,filter=nearest", width: 30, height: 20, wantWidth: 10, wantHeight: 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			color := [3]byte{10, 120, 250}
+			testBmp := newTestBmp(test.width, test.height, color)
+
+			if err := testBmp.Resize(test.value); err != nil {
+				t.Fatalf("Resize() error = %v", err)
+			}
+			if testBmp.dibHeader.Width != test.wantWidth || testBmp.dibHeader.Height != test.wantHeight {
+				t.Fatalf("Resize() size = %dx%d, want %dx%d", testBmp.dibHeader.Width, testBmp.dibHeader.Height, test.wantWidth, test.wantHeight)
+			}
+
+			// Row padding and file size must match the new dimensions
+			wantRowSize := (24*test.wantWidth + 31) / 32 * 4
+			if len(testBmp.pixelArray) != int(test.wantHeight) || len(testBmp.pixelArray[0]) != int(wantRowSize) {
+				t.Fatalf("Resize() pixel array is %dx%d bytes, want %dx%d", len(testBmp.pixelArray[0]), len(testBmp.pixelArray), wantRowSize, test.wantHeight)
+			}
+			if testBmp.fileHeader.FileSize != 54+wantRowSize*test.wantHeight {
+				t.Errorf("Resize() file size = %d, want %d", testBmp.fileHeader.FileSize, 54+wantRowSize*test.wantHeight)
+			}
+
+			// Flat color stays the same with normalized weights
+			for _, row := range testBmp.pixelArray {
+				for colIdx := uint32(0); colIdx < test.wantWidth; colIdx++ {
+					if [3]byte(row[colIdx*3:colIdx*3+3]) != color {
+						t.Fatalf("Resize() pixel = %v, want %v", row[colIdx*3:colIdx*3+3], color)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestResizeTooLarge(t *testing.T) {
+	for _, value := range []string{"100000x100000", "4294967297x1", "0x100000000", "9000x9000:mode=letterbox", "1x100000:mode=fill"} {
+		testBmp := newTestBmp(30, 20, [3]byte{})
+		if err := testBmp.Resize(value); err != ErrImageTooLarge {
+			t.Errorf("Resize(%q) error = %v, want %v", value, err, ErrImageTooLarge)
+		}
+		// Image stays untouched
+		if testBmp.dibHeader.Width != 30 || len(testBmp.pixelArray) != 20 {
+			t.Errorf("Resize(%q) changed size to %dx%d", value, testBmp.dibHeader.Width, len(testBmp.pixelArray))
+		}
+	}
+
+	testBmp := newTestBmp(30, 20, [3]byte{})
+	for _, value := range []string{"100000%", "1e30%"} {
+		if err := testBmp.Scale(value); err != ErrImageTooLarge {
+			t.Errorf("Scale(%q) error = %v, want %v", value, err, ErrImageTooLarge)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package bmp
+
+import (
+	"errors"
+)
+
+// Errors
+var (
+	ErrIncorrectMaskSize = errors.New("Mask image must have the same size as the image")
+)
+
+// Energy added to pixels of protect mask and subtracted from pixels of remove mask,
+// it's larger than any gradient, so seams avoid or go through the masked pixels
+const maskEnergy = 1e6
+
+// Image being carved, rows go from the top and every pixel keeps its energy bias of masks
+type carving struct {
+	pixels [][][3]byte
+	bias   [][]float64
+}
+
+// seamCarve changes dimensions of the image removing or inserting seams of the lowest energy,
+// so that the content with strong details is not distorted
+// mask images mark pixels with luma above the middle, protect and remove may be empty
+// see (https://en.wikipedia.org/wiki/Seam_carving)
+func (b *bmp) seamCarve(width, height int, protect, remove string) error {
+	c := &carving{
+		pixels: make([][][3]byte, len(b.pixelArray)),
+		bias:   make([][]float64, len(b.pixelArray)),
+	}
+	for y := range c.pixels {
+		row := b.row(y)
+		c.pixels[y] = make([][3]byte, b.dibHeader.Width)
+		c.bias[y] = make([]float64, b.dibHeader.Width)
+		for x := range c.pixels[y] {
+			c.pixels[y][x] = [3]byte{row[x*3], row[x*3+1], row[x*3+2]}
+		}
+	}
+
+	for _, mask := range []struct {
+		fileName string
+		energy   float64
+	}{{protect, maskEnergy}, {remove, -maskEnergy}} {
+		if mask.fileName == "" {
+			continue
+		}
+		layer, err := Load(mask.fileName)
+		if err != nil {
+			return err
+		}
+		if layer.dibHeader.Width != b.dibHeader.Width || layer.dibHeader.Height != b.dibHeader.Height {
+			return ErrIncorrectMaskSize
+		}
+		plane := layer.lumaPlane()
+		for y := range c.bias {
+			for x, luma := range plane[len(plane)-1-y] {
+				if luma > 127 {
+					c.bias[y][x] += mask.energy
+				}
+			}
+		}
+	}
+
+	// Width is carved first, height is carved as width of transposed image
+	c.carveWidth(width)
+	c.transpose()
+	c.carveWidth(height)
+	c.transpose()
+
+	b.setSize(uint32(width), uint32(height))
+	for y, pixels := range c.pixels {
+		row := b.row(y)
+		for x, pixel := range pixels {
+			copy(row[x*3:x*3+3], pixel[:])
+		}
+	}
+
+	return nil
+}
+
+// carveWidth removes or inserts vertical seams until the image has the width
+func (c *carving) carveWidth(width int) {
+	for len(c.pixels[0]) > width {
+		c.removeSeam(c.findSeam())
+	}
+	if len(c.pixels[0]) < width {
+		c.insertSeams(width - len(c.pixels[0]))
+	}
+}
+
+// energy returns the sum of absolute color gradients of every pixel with masks bias,
+// pixels outside of image are replaced with the nearest border pixels
+func (c *carving) energy() [][]float64 {
+	height, width := len(c.pixels), len(c.pixels[0])
+	energy := make([][]float64, height)
+
+	for y := range energy {
+		energy[y] = make([]float64, width)
+		up, down := c.pixels[max(y-1, 0)], c.pixels[min(y+1, height-1)]
+		for x := range energy[y] {
+			left, right := c.pixels[y][max(x-1, 0)], c.pixels[y][min(x+1, width-1)]
+			gradient := 0
+			for channel := 0; channel < 3; channel++ {
+				gradient += absDiff(left[channel], right[channel]) + absDiff(up[x][channel], down[x][channel])
+			}
+			energy[y][x] = float64(gradient) + c.bias[y][x]
+		}
+	}
+
+	return energy
+}
+
+// absDiff returns the absolute difference of values
+func absDiff(a, b byte) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+// findSeam returns the column of every row of the connected vertical path with the lowest total energy,
+// found by dynamic programming from the top row
+func (c *carving) findSeam() []int {
+	cumulative := c.energy()
+	height, width := len(cumulative), len(cumulative[0])
+
+	for y := 1; y < height; y++ {
+		for x := 0; x < width; x++ {
+			best := cumulative[y-1][x]
+			if x > 0 {
+				best = min(best, cumulative[y-1][x-1])
+			}
+			if x < width-1 {
+				best = min(best, cumulative[y-1][x+1])
+			}
+			cumulative[y][x] += best
+		}
+	}
+
+	// Backtracking from the lowest total energy of the bottom row
+	seam := make([]int, height)
+	for x := 1; x < width; x++ {
+		if cumulative[height-1][x] < cumulative[height-1][seam[height-1]] {
+			seam[height-1] = x
+		}
+	}
+	for y := height - 2; y >= 0; y-- {
+		prev := seam[y+1]
+		seam[y] = prev
+		for x := max(prev-1, 0); x <= min(prev+1, width-1); x++ {
+			if cumulative[y][x] < cumulative[y][seam[y]] {
+				seam[y] = x
+			}
+		}
+	}
+
+	return seam
+}
+
+// removeSeam deletes the pixel of seam from every row
+func (c *carving) removeSeam(seam []int) {
+	for y, x := range seam {
+		c.pixels[y] = append(c.pixels[y][:x], c.pixels[y][x+1:]...)
+		c.bias[y] = append(c.bias[y][:x], c.bias[y][x+1:]...)
+	}
+}
+
+// insertSeams duplicates the seams which would be removed first, inserted pixels are averages of seam and its right neighbor,
+// seams are inserted by batches of at most half of the width, so the same seam is not duplicated over and over
+func (c *carving) insertSeams(count int) {
+	for count > 0 {
+		height, width := len(c.pixels), len(c.pixels[0])
+		batch := max(min(count, width/2), 1)
+
+		// Seams are found on the copy, original columns of its pixels are tracked
+		work := &carving{pixels: make([][][3]byte, height), bias: make([][]float64, height)}
+		columns := make([][]int, height)
+		for y := range c.pixels {
+			work.pixels[y] = append([][3]byte{}, c.pixels[y]...)
+			work.bias[y] = append([]float64{}, c.bias[y]...)
+			columns[y] = make([]int, width)
+			for x := range columns[y] {
+				columns[y][x] = x
+			}
+		}
+
+		// Number of duplicates of every original pixel
+		duplicates := make([][]int, height)
+		for y := range duplicates {
+			duplicates[y] = make([]int, width)
+		}
+		// The only column has no seam to find, it is duplicated itself
+		if width == 1 {
+			for y := range duplicates {
+				duplicates[y][0] = batch
+			}
+		}
+		for seamIdx := 0; seamIdx < batch && len(work.pixels[0]) > 1; seamIdx++ {
+			seam := work.findSeam()
+			for y, x := range seam {
+				duplicates[y][columns[y][x]]++
+				columns[y] = append(columns[y][:x], columns[y][x+1:]...)
+			}
+			work.removeSeam(seam)
+		}
+
+		inserted := 0
+		for y := range c.pixels {
+			pixels := make([][3]byte, 0, width+batch)
+			bias := make([]float64, 0, width+batch)
+			for x, pixel := range c.pixels[y] {
+				pixels = append(pixels, pixel)
+				bias = append(bias, c.bias[y][x])
+
+				neighbor := c.pixels[y][min(x+1, width-1)]
+				for duplicate := 0; duplicate < duplicates[y][x]; duplicate++ {
+					pixels = append(pixels, [3]byte{
+						byte((int(pixel[0]) + int(neighbor[0])) / 2),
+						byte((int(pixel[1]) + int(neighbor[1])) / 2),
+						byte((int(pixel[2]) + int(neighbor[2])) / 2),
+					})
+					// Inserted pixels are protected, so the next batch selects other seams
+					bias = append(bias, c.bias[y][x]+maskEnergy)
+				}
+			}
+			c.pixels[y], c.bias[y] = pixels, bias
+			inserted = len(pixels) - width
+		}
+
+		count -= inserted
+	}
+}
+
+// transpose swaps rows and columns of the image
+func (c *carving) transpose() {
+	height, width := len(c.pixels), len(c.pixels[0])
+	pixels := make([][][3]byte, width)
+	bias := make([][]float64, width)
+	for x := range pixels {
+		pixels[x] = make([][3]byte, height)
+		bias[x] = make([]float64, height)
+		for y := range pixels[x] {
+			pixels[x][y] = c.pixels[y][x]
+			bias[x][y] = c.bias[y][x]
+		}
+	}
+	c.pixels, c.bias = pixels, bias
+}
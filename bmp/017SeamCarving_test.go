@@ -0,0 +1,50 @@
+package bmp
+
+import "testing"
+
+func TestSeamCarve(t *testing.T) {
+	// White image with red vertical line, seams must go around the line
+	testBmp := newTestBmp(10, 6, [3]byte{255, 255, 255})
+	for _, row := range testBmp.pixelArray {
+		copy(row[5*3:], []byte{0, 0, 255})
+	}
+
+	for _, size := range [][2]int{{6, 6}, {14, 4}} {
+		if err := testBmp.seamCarve(size[0], size[1], "", ""); err != nil {
+			t.Fatalf("seamCarve() error = %v", err)
+		}
+		if int(testBmp.dibHeader.Width) != size[0] || int(testBmp.dibHeader.Height) != size[1] {
+			t.Fatalf("seamCarve() size = %dx%d, want %dx%d", testBmp.dibHeader.Width, testBmp.dibHeader.Height, size[0], size[1])
+		}
+
+		for rowIdx, row := range testBmp.pixelArray {
+			found := false
+			for colIdx := 0; colIdx < size[0]; colIdx++ {
+				if [3]byte(row[colIdx*3:colIdx*3+3]) == [3]byte{0, 0, 255} {
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf("seamCarve() to %dx%d removed the line from row %d", size[0], size[1], rowIdx)
+			}
+		}
+	}
+
+	// Images of single column or row have no seam to duplicate
+	for _, size := range [][4]int{{1, 5, 10, 5}, {5, 1, 5, 10}} {
+		thinBmp := newTestBmp(size[0], size[1], [3]byte{10, 20, 30})
+		if err := thinBmp.seamCarve(size[2], size[3], "", ""); err != nil {
+			t.Fatalf("seamCarve() of %dx%d error = %v", size[0], size[1], err)
+		}
+		if int(thinBmp.dibHeader.Width) != size[2] || int(thinBmp.dibHeader.Height) != size[3] {
+			t.Fatalf("seamCarve() of %dx%d size = %dx%d, want %dx%d", size[0], size[1], thinBmp.dibHeader.Width, thinBmp.dibHeader.Height, size[2], size[3])
+		}
+		for rowIdx, row := range thinBmp.pixelArray {
+			for colIdx := 0; colIdx < size[2]; colIdx++ {
+				if [3]byte(row[colIdx*3:colIdx*3+3]) != [3]byte{10, 20, 30} {
+					t.Fatalf("seamCarve() of %dx%d pixel (%d, %d) = %v, want the fill color", size[0], size[1], colIdx, rowIdx, row[colIdx*3:colIdx*3+3])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+)
+
+// Errors
+var (
+	ErrIncorrectThumbnailSize = errors.New("Incorrect thumbnail size provided")
+)
+
+// Radius of unsharp mask blur, thumbnails are small, so only the finest details are sharpened
+const sharpenRadius = 1
+
+// Thumbnail downsamples the image to fit inside of the square with the side of size preserving aspect ratio,
+// images smaller than the size are not enlarged, amount of unsharp mask restores details lost on downsampling, zero disables it
+func (b *bmp) Thumbnail(size int, sharpen float64) error {
+	if size < 1 {
+		return ErrIncorrectThumbnailSize
+	}
+
+	sourceWidth, sourceHeight := float64(b.dibHeader.Width), float64(len(b.pixelArray))
+	scale := min(float64(size)/sourceWidth, float64(size)/sourceHeight)
+	if scale < 1 {
+		width := max(int(math.Round(sourceWidth*scale)), 1)
+		height := max(int(math.Round(sourceHeight*scale)), 1)
+		if err := b.resample(width, height, "lanczos3"); err != nil {
+			return err
+		}
+	}
+
+	if sharpen > 0 {
+		b.sharpen(sharpen)
+	}
+	return nil
+}
+
+// sharpen applies the unsharp mask: every channel value is moved away from its gaussian blurred value by the amount
+// see (https://en.wikipedia.org/wiki/Unsharp_masking)
+func (b *bmp) sharpen(amount float64) {
+	for channel := 0; channel < 3; channel++ {
+		plane := make([][]byte, len(b.pixelArray))
+		for rowIdx, row := range b.pixelArray {
+			plane[rowIdx] = make([]byte, b.dibHeader.Width)
+			for colIdx := range plane[rowIdx] {
+				plane[rowIdx][colIdx] = row[colIdx*3+channel]
+			}
+		}
+
+		blurred := gaussianMean(plane, sharpenRadius)
+		for rowIdx, row := range b.pixelArray {
+			for colIdx, value := range plane[rowIdx] {
+				row[colIdx*3+channel] = clampByte(float64(value) + amount*(float64(value)-blurred[rowIdx][colIdx]))
+			}
+		}
+	}
+}
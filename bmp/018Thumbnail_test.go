@@ -0,0 +1,36 @@
+package bmp
+
+import "testing"
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		width, height int
+		size          int
+		wantWidth     int
+		wantHeight    int
+	}{
+		{400, 300, 100, 100, 75},
+		{300, 400, 100, 75, 100},
+		// Small images are not enlarged
+		{60, 40, 100, 60, 40},
+	}
+
+	for _, test := range tests {
+		testBmp := newTestBmp(test.width, test.height, [3]byte{10, 200, 100})
+		if err := testBmp.Thumbnail(test.size, 0.5); err != nil {
+			t.Fatalf("Thumbnail() error = %v", err)
+		}
+		if int(testBmp.dibHeader.Width) != test.wantWidth || int(testBmp.dibHeader.Height) != test.wantHeight {
+			t.Errorf("Thumbnail() of %dx%d size = %dx%d, want %dx%d", test.width, test.height,
+				testBmp.dibHeader.Width, testBmp.dibHeader.Height, test.wantWidth, test.wantHeight)
+		}
+		// Sharpening doesn't change solid color
+		if pixel := [3]byte(testBmp.row(0)[:3]); pixel != [3]byte{10, 200, 100} {
+			t.Errorf("Thumbnail() pixel = %v, want %v", pixel, [3]byte{10, 200, 100})
+		}
+	}
+
+	if err := newTestBmp(10, 10, [3]byte{}).Thumbnail(0, 0); err != ErrIncorrectThumbnailSize {
+		t.Errorf("Thumbnail() error = %v, want %v", err, ErrIncorrectThumbnailSize)
+	}
+}
@@ -0,0 +1,194 @@
+package bmp
+
+import (
+	"errors"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectCanvasValue = errors.New("Incorrect pad, canvas or border value provided")
+	ErrIncorrectAnchor      = errors.New("Incorrect canvas anchor provided")
+)
+
+// Positions of image on canvas by anchor name, 0 is the left or top side, 1 is the center, 2 is the right or bottom side
+var canvasAnchors = map[string][2]int{
+	"top-left":     {0, 0},
+	"top":          {1, 0},
+	"top-right":    {2, 0},
+	"left":         {0, 1},
+	"center":       {1, 1},
+	"right":        {2, 1},
+	"bottom-left":  {0, 2},
+	"bottom":       {1, 2},
+	"bottom-right": {2, 2},
+}
+
+// Fill of canvas area outside of image: solid color, replicated edge pixels or mirrored image
+type canvasFill struct {
+	mode  string
+	color [3]byte
+}
+
+// Pad adds margins to the sides of image
+// value format: <top>,<right>,<bottom>,<left>[:<fill>] or <margin>[:<fill>] for all sides
+// fill is the color rrggbb (black by default), edge to replicate edge pixels or mirror to reflect the image
+func (b *bmp) Pad(flagValue string) error {
+	marginsValue, fillValue := splitCanvasValue(flagValue)
+	fill, err := parseCanvasFill(fillValue)
+	if err != nil {
+		return err
+	}
+
+	var margins [4]int
+	values := utils.Split(marginsValue, ",")
+	if len(values) != 1 && len(values) != 4 {
+		return ErrIncorrectCanvasValue
+	}
+	for idx := range margins {
+		margin, ok := utils.Atoi(values[idx%len(values)])
+		if !ok || margin < 0 {
+			return ErrIncorrectCanvasValue
+		} else if margin > maxPixels {
+			// Sum of margins and image side mustn't overflow
+			return ErrImageTooLarge
+		}
+		margins[idx] = margin
+	}
+
+	top, right, bottom, left := margins[0], margins[1], margins[2], margins[3]
+	return b.extend(int(b.dibHeader.Width)+left+right, len(b.pixelArray)+top+bottom, left, top, fill)
+}
+
+// Border frames the image with the border of the same width on every side
+// value format: <width>[:<fill>], fill is the same as of pad
+func (b *bmp) Border(flagValue string) error {
+	widthValue, fillValue := splitCanvasValue(flagValue)
+	width, ok := utils.Atoi(widthValue)
+	if !ok || width < 1 {
+		return ErrIncorrectCanvasValue
+	} else if width > maxPixels {
+		return ErrImageTooLarge
+	}
+	fill, err := parseCanvasFill(fillValue)
+	if err != nil {
+		return err
+	}
+
+	return b.extend(int(b.dibHeader.Width)+2*width, len(b.pixelArray)+2*width, width, width, fill)
+}
+
+// Canvas changes the canvas size keeping the image at the anchor, parts of image outside of smaller canvas are cut
+// value format: <width>x<height>[:anchor=<anchor>,color=<fill>]
+// anchors: top-left, top, top-right, left, center (default), right, bottom-left, bottom, bottom-right
+func (b *bmp) Canvas(flagValue string) error {
+	size, options, err := splitResizeValue(flagValue)
+	if err != nil {
+		return ErrIncorrectCanvasValue
+	}
+
+	dimensions := utils.Split(size, "x")
+	if len(dimensions) != 2 {
+		return ErrIncorrectCanvasValue
+	}
+	width, ok := utils.Atoi(dimensions[0])
+	if !ok || width < 1 {
+		return ErrIncorrectCanvasValue
+	}
+	height, ok := utils.Atoi(dimensions[1])
+	if !ok || height < 1 {
+		return ErrIncorrectCanvasValue
+	}
+
+	anchor, fill := canvasAnchors["center"], canvasFill{mode: "color"}
+	for key, value := range options {
+		switch key {
+		case "anchor":
+			if anchor, ok = canvasAnchors[value]; !ok {
+				return ErrIncorrectAnchor
+			}
+		case "color":
+			if fill, err = parseCanvasFill(value); err != nil {
+				return err
+			}
+		default:
+			return ErrIncorrectCanvasValue
+		}
+	}
+
+	x := (width - int(b.dibHeader.Width)) * anchor[0] / 2
+	y := (height - len(b.pixelArray)) * anchor[1] / 2
+	return b.extend(width, height, x, y, fill)
+}
+
+// splitCanvasValue splits the value to the size and fill after colon
+func splitCanvasValue(flagValue string) (string, string) {
+	for idx := range flagValue {
+		if flagValue[idx] == ':' {
+			return flagValue[:idx], flagValue[idx+1:]
+		}
+	}
+	return flagValue, ""
+}
+
+// parseCanvasFill parses the fill of canvas: color rrggbb, edge or mirror, empty value is black color
+func parseCanvasFill(value string) (canvasFill, error) {
+	switch value {
+	case "edge", "mirror":
+		return canvasFill{mode: value}, nil
+	case "":
+		return canvasFill{mode: "color"}, nil
+	}
+
+	red, green, blue, ok := utils.ParseHexColor(value)
+	if !ok {
+		return canvasFill{}, ErrIncorrectColorValue
+	}
+	return canvasFill{mode: "color", color: [3]byte{blue, green, red}}, nil
+}
+
+// extend puts the image on the canvas with top left corner of image at x, y,
+// the area outside of image is filled by the fill, canvas can't exceed the limit of pixels
+func (b *bmp) extend(width, height, x, y int, fill canvasFill) error {
+	if err := checkSize(width, height); err != nil {
+		return err
+	}
+	if fill.mode == "color" {
+		b.placeOnCanvas(width, height, x, y, fill.color)
+		return nil
+	}
+
+	source := b.pixelArray
+	sourceWidth, sourceHeight := int(b.dibHeader.Width), len(source)
+	b.setSize(uint32(width), uint32(height))
+
+	for rowIdx := 0; rowIdx < height; rowIdx++ {
+		row := b.row(rowIdx)
+		sourceRow := source[sourceHeight-1-fill.index(rowIdx-y, sourceHeight)]
+		for colIdx := 0; colIdx < width; colIdx++ {
+			sourceColIdx := fill.index(colIdx-x, sourceWidth)
+			copy(row[colIdx*3:colIdx*3+3], sourceRow[sourceColIdx*3:sourceColIdx*3+3])
+		}
+	}
+
+	return nil
+}
+
+// index maps the coordinate outside of image side with the size to the coordinate of source pixel,
+// edge takes the nearest pixel, mirror reflects the image including its edge pixels
+func (f canvasFill) index(idx, size int) int {
+	if f.mode == "edge" {
+		return min(max(idx, 0), size-1)
+	}
+
+	period := 2 * size
+	idx %= period
+	if idx < 0 {
+		idx += period
+	}
+	if idx >= size {
+		idx = period - 1 - idx
+	}
+	return idx
+}
@@ -0,0 +1,80 @@
+package bmp
+
+import "testing"
+
+func TestPad(t *testing.T) {
+	// Row of 3 pixels with blue values 1, 2, 3 is padded by 2 pixels on the left and right
+	tests := []struct {
+		value string
+		want  []byte
+	}{
+		{"0,2,0,2:edge", []byte{1, 1, 1, 2, 3, 3, 3}},
+		{"0,2,0,2:mirror", []byte{2, 1, 1, 2, 3, 3, 2}},
+		{"0,2,0,2:0000ff", []byte{255, 255, 1, 2, 3, 255, 255}},
+	}
+
+	for _, test := range tests {
+		testBmp := newTestBmp(3, 1, [3]byte{})
+		for colIdx := 0; colIdx < 3; colIdx++ {
+			testBmp.pixelArray[0][colIdx*3] = byte(colIdx + 1)
+		}
+
+		if err := testBmp.Pad(test.value); err != nil {
+			t.Fatalf("Pad(%s) error = %v", test.value, err)
+		}
+		if int(testBmp.dibHeader.Width) != len(test.want) || testBmp.dibHeader.Height != 1 {
+			t.Fatalf("Pad(%s) size = %dx%d, want %dx1", test.value, testBmp.dibHeader.Width, testBmp.dibHeader.Height, len(test.want))
+		}
+		for colIdx, want := range test.want {
+			if blue := testBmp.pixelArray[0][colIdx*3]; blue != want {
+				t.Errorf("Pad(%s) blue of pixel %d = %d, want %d", test.value, colIdx, blue, want)
+			}
+		}
+	}
+}
+
+func TestCanvas(t *testing.T) {
+	testBmp := newTestBmp(4, 2, [3]byte{255, 255, 255})
+	if err := testBmp.Canvas("6x6:anchor=bottom-right"); err != nil {
+		t.Fatalf("Canvas() error = %v", err)
+	}
+	if testBmp.dibHeader.Width != 6 || testBmp.dibHeader.Height != 6 {
+		t.Fatalf("Canvas() size = %dx%d, want 6x6", testBmp.dibHeader.Width, testBmp.dibHeader.Height)
+	}
+	// Image is at the bottom right corner, the rest is black
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 6; x++ {
+			want := [3]byte{}
+			if x >= 2 && y >= 4 {
+				want = [3]byte{255, 255, 255}
+			}
+			if pixel := [3]byte(testBmp.row(y)[x*3:]); pixel != want {
+				t.Errorf("Canvas() pixel %d,%d = %v, want %v", x, y, pixel, want)
+			}
+		}
+	}
+}
+
+func TestCanvasTooLarge(t *testing.T) {
+	// Huge margins would wrap the int sum or uint32 size, sides are limited by the pixels of canvas
+	tests := []struct {
+		name  string
+		apply func(b *bmp) error
+	}{
+		{"Pad", func(b *bmp) error { return b.Pad("9000,0,0,0") }},
+		{"Pad wrapping int", func(b *bmp) error { return b.Pad("0,4611686018427387904,0,4611686018427387904") }},
+		{"Pad wrapping uint32", func(b *bmp) error { return b.Pad("0,4294967295,0,0:edge") }},
+		{"Border", func(b *bmp) error { return b.Border("5000:mirror") }},
+		{"Canvas", func(b *bmp) error { return b.Canvas("100000x100000") }},
+	}
+
+	for _, test := range tests {
+		testBmp := newTestBmp(8192, 1, [3]byte{})
+		if err := test.apply(testBmp); err != ErrImageTooLarge {
+			t.Errorf("%s error = %v, want %v", test.name, err, ErrImageTooLarge)
+		}
+		if testBmp.dibHeader.Width != 8192 || len(testBmp.pixelArray) != 1 {
+			t.Errorf("%s changed size to %dx%d", test.name, testBmp.dibHeader.Width, len(testBmp.pixelArray))
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package bmp
+
+import (
+	"errors"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectTrimValue = errors.New("Incorrect trim tolerance provided, value from 0 to 255 expected")
+)
+
+// Default difference of channel value from the border color which is still considered as border, it absorbs scan noise
+const trimTolerance = 10
+
+// Trim crops uniform borders, border color is the color of image corners which the most corners match within the tolerance,
+// returns the box of content which is left, the image is not changed when there is no content
+// value format: empty or <tolerance> from 0 to 255
+func (b *bmp) Trim(flagValue string) (x, y, width, height int, err error) {
+	tolerance := trimTolerance
+	if flagValue != "" {
+		var ok bool
+		if tolerance, ok = utils.Atoi(flagValue); !ok || tolerance < 0 || tolerance > 255 {
+			return 0, 0, 0, 0, ErrIncorrectTrimValue
+		}
+	}
+
+	imageWidth, imageHeight := int(b.dibHeader.Width), len(b.pixelArray)
+	border := b.borderColor(tolerance)
+
+	// Bounding box of pixels differing from the border color
+	left, top, right, bottom := imageWidth, imageHeight, -1, -1
+	for rowIdx := 0; rowIdx < imageHeight; rowIdx++ {
+		row := b.row(rowIdx)
+		for colIdx := 0; colIdx < imageWidth; colIdx++ {
+			if !similarColors([3]byte(row[colIdx*3:colIdx*3+3]), border, tolerance) {
+				left, right = min(left, colIdx), max(right, colIdx)
+				top, bottom = min(top, rowIdx), max(bottom, rowIdx)
+			}
+		}
+	}
+
+	if right < 0 {
+		return 0, 0, imageWidth, imageHeight, nil
+	}
+
+	b.crop(left, top, right-left+1, bottom-top+1)
+	return left, top, right - left + 1, bottom - top + 1, nil
+}
+
+// borderColor returns the corner color which the most corners of image match within the tolerance,
+// so noise of scan doesn't split the votes, top left corner wins a tie
+func (b *bmp) borderColor(tolerance int) [3]byte {
+	width, height := int(b.dibHeader.Width), len(b.pixelArray)
+	corners := [][3]byte{}
+	for _, corner := range [][2]int{{0, 0}, {width - 1, 0}, {0, height - 1}, {width - 1, height - 1}} {
+		row := b.row(corner[1])
+		corners = append(corners, [3]byte(row[corner[0]*3:corner[0]*3+3]))
+	}
+
+	best, bestCount := corners[0], 0
+	for _, color := range corners {
+		count := 0
+		for _, other := range corners {
+			if similarColors(color, other, tolerance) {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = color, count
+		}
+	}
+	return best
+}
+
+// similarColors reports whether every channel of colors differs by the tolerance at most
+func similarColors(first, second [3]byte, tolerance int) bool {
+	for channel := range first {
+		if absDiff(first[channel], second[channel]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package bmp
+
+import "testing"
+
+func TestTrim(t *testing.T) {
+	// White image with noisy margins and dark content of 3x2 pixels at 4, 5
+	testBmp := newTestBmp(10, 9, [3]byte{255, 255, 255})
+	testBmp.row(1)[2*3] = 250
+	for rowIdx := 5; rowIdx < 7; rowIdx++ {
+		for colIdx := 4; colIdx < 7; colIdx++ {
+			copy(testBmp.row(rowIdx)[colIdx*3:], []byte{20, 20, 20})
+		}
+	}
+
+	x, y, width, height, err := testBmp.Trim("")
+	if err != nil {
+		t.Fatalf("Trim() error = %v", err)
+	}
+	if x != 4 || y != 5 || width != 3 || height != 2 {
+		t.Errorf("Trim() box = %dx%d at %d,%d, want 3x2 at 4,5", width, height, x, y)
+	}
+	checkTrimmedContent(t, testBmp)
+
+	if _, _, _, _, err := testBmp.Trim("256"); err != ErrIncorrectTrimValue {
+		t.Errorf("Trim() error = %v, want %v", err, ErrIncorrectTrimValue)
+	}
+}
+
+func TestTrimNoisyCorners(t *testing.T) {
+	// Dark content touches the top left corner, the other corners are white with noise of scan,
+	// they outvote the content only when compared within the tolerance
+	testBmp := newTestBmp(8, 6, [3]byte{255, 255, 255})
+	copy(testBmp.row(0)[7*3:], []byte{254, 254, 254})
+	copy(testBmp.row(5)[0:], []byte{253, 253, 253})
+	copy(testBmp.row(5)[7*3:], []byte{252, 252, 252})
+	for rowIdx := 0; rowIdx < 2; rowIdx++ {
+		for colIdx := 0; colIdx < 3; colIdx++ {
+			copy(testBmp.row(rowIdx)[colIdx*3:], []byte{20, 20, 20})
+		}
+	}
+
+	x, y, width, height, err := testBmp.Trim("")
+	if err != nil {
+		t.Fatalf("Trim() error = %v", err)
+	}
+	if x != 0 || y != 0 || width != 3 || height != 2 {
+		t.Errorf("Trim() box = %dx%d at %d,%d, want 3x2 at 0,0", width, height, x, y)
+	}
+	checkTrimmedContent(t, testBmp)
+
+	// Uniform image has no content and stays the same
+	testBmp = newTestBmp(4, 3, [3]byte{255, 255, 255})
+	if x, y, width, height, _ := testBmp.Trim(""); x != 0 || y != 0 || width != 4 || height != 3 || testBmp.dibHeader.Width != 4 {
+		t.Errorf("Trim() of uniform image box = %dx%d at %d,%d, want 4x3 at 0,0", width, height, x, y)
+	}
+}
+
+// checkTrimmedContent checks that the image is cropped to the dark content of 3x2 pixels
+func checkTrimmedContent(t *testing.T, testBmp *bmp) {
+	t.Helper()
+	if testBmp.dibHeader.Width != 3 || len(testBmp.pixelArray) != 2 {
+		t.Fatalf("Trim() size = %dx%d, want 3x2", testBmp.dibHeader.Width, len(testBmp.pixelArray))
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			if pixel := [3]byte(testBmp.row(y)[x*3:]); pixel != [3]byte{20, 20, 20} {
+				t.Errorf("Trim() pixel %d,%d = %v, want content color", x, y, pixel)
+			}
+		}
+	}
+}
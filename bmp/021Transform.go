@@ -0,0 +1,384 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectAffineValue      = errors.New("Incorrect affine transform value provided")
+	ErrIncorrectPerspectiveValue = errors.New("Incorrect perspective value provided, 4 points x,y expected")
+	ErrIncorrectSamplingFilter   = errors.New("Incorrect sampling filter provided, nearest, bilinear or bicubic expected")
+	ErrSingularTransform         = errors.New("Transform is not invertible")
+)
+
+// Default sampling filter of transforms
+const samplingFilter = "bilinear"
+
+// Affine matrix 2x3 maps x, y to a*x + b*y + c, d*x + e*y + f
+// see (https://en.wikipedia.org/wiki/Affine_transformation#Image_transformation)
+type affineMatrix [2][3]float64
+
+// multiply returns the matrix of transform m applied after transform n
+func (m affineMatrix) multiply(n affineMatrix) affineMatrix {
+	var result affineMatrix
+	for row := 0; row < 2; row++ {
+		result[row][0] = m[row][0]*n[0][0] + m[row][1]*n[1][0]
+		result[row][1] = m[row][0]*n[0][1] + m[row][1]*n[1][1]
+		result[row][2] = m[row][0]*n[0][2] + m[row][1]*n[1][2] + m[row][2]
+	}
+	return result
+}
+
+// invert returns the inverse transform, ok is false when matrix is singular
+func (m affineMatrix) invert() (affineMatrix, bool) {
+	determinant := m[0][0]*m[1][1] - m[0][1]*m[1][0]
+	if math.Abs(determinant) < 1e-12 {
+		return affineMatrix{}, false
+	}
+	a, b, d, e := m[1][1]/determinant, -m[0][1]/determinant, -m[1][0]/determinant, m[0][0]/determinant
+	return affineMatrix{
+		{a, b, -a*m[0][2] - b*m[1][2]},
+		{d, e, -d*m[0][2] - e*m[1][2]},
+	}, true
+}
+
+// apply returns the transformed point
+func (m affineMatrix) apply(x, y float64) (float64, float64) {
+	return m[0][0]*x + m[0][1]*y + m[0][2], m[1][0]*x + m[1][1]*y + m[1][2]
+}
+
+// Affine transforms the image keeping its size, coordinates go from the top left pixel
+// value format: <a>,<b>,<c>,<d>,<e>,<f>[:<options>] where matrix maps source x, y to a*x + b*y + c, d*x + e*y + f,
+// or <options> with transform parameters applied around the image center in order scale, shear, rotate, translate:
+// scale=<s|sx x sy>, shear=<kx>x<ky>, rotate=<degrees clockwise>, translate=<tx>x<ty>
+// sampling options: filter=<nearest|bilinear|bicubic>, color=<rrggbb|edge|mirror> fills pixels outside of image
+func (b *bmp) Affine(flagValue string) error {
+	matrixValue, rest := "", flagValue
+	if flagValue != "" && (flagValue[0] >= '0' && flagValue[0] <= '9' || flagValue[0] == '-' || flagValue[0] == '.') {
+		matrixValue, rest = splitCanvasValue(flagValue)
+	}
+
+	options := map[string]string{}
+	if rest != "" {
+		var ok bool
+		if options, ok = utils.ParseOptions(rest); !ok {
+			return ErrIncorrectAffineValue
+		}
+	}
+	filter, fill, err := samplingOptions(options, ErrIncorrectAffineValue, "scale", "shear", "rotate", "translate")
+	if err != nil {
+		return err
+	}
+
+	matrix := affineMatrix{{1, 0, 0}, {0, 1, 0}}
+	if matrixValue != "" {
+		values := utils.Split(matrixValue, ",")
+		if len(values) != 6 {
+			return ErrIncorrectAffineValue
+		}
+		// Matrix and transform parameters are exclusive
+		for _, key := range []string{"scale", "shear", "rotate", "translate"} {
+			if _, ok := options[key]; ok {
+				return ErrIncorrectAffineValue
+			}
+		}
+		for idx, value := range values {
+			number, ok := utils.ParseFloat(value)
+			if !ok {
+				return ErrIncorrectAffineValue
+			}
+			matrix[idx/3][idx%3] = number
+		}
+	} else {
+		if matrix, err = b.affineParameters(options); err != nil {
+			return err
+		}
+	}
+
+	inverse, ok := matrix.invert()
+	if !ok {
+		return ErrSingularTransform
+	}
+	b.warp(int(b.dibHeader.Width), len(b.pixelArray), inverse.apply, filter, fill)
+	return nil
+}
+
+// affineParameters returns the matrix of scale, shear, rotation and translation around the image center
+func (b *bmp) affineParameters(options map[string]string) (affineMatrix, error) {
+	// pair parses two numbers separated by x, single number is used for both when allowed
+	pair := func(value string, single bool) (float64, float64, bool) {
+		values := utils.Split(value, "x")
+		if len(values) == 1 && single {
+			number, ok := utils.ParseFloat(values[0])
+			return number, number, ok
+		} else if len(values) != 2 {
+			return 0, 0, false
+		}
+		first, firstOk := utils.ParseFloat(values[0])
+		second, secondOk := utils.ParseFloat(values[1])
+		return first, second, firstOk && secondOk
+	}
+
+	matrix := affineMatrix{{1, 0, 0}, {0, 1, 0}}
+
+	for _, key := range []string{"scale", "shear", "rotate", "translate"} {
+		value, ok := options[key]
+		if !ok {
+			continue
+		}
+		var step affineMatrix
+		switch key {
+		case "scale":
+			sx, sy, ok := pair(value, true)
+			if !ok || sx == 0 || sy == 0 {
+				return matrix, ErrIncorrectAffineValue
+			}
+			step = affineMatrix{{sx, 0, 0}, {0, sy, 0}}
+		case "shear":
+			kx, ky, ok := pair(value, false)
+			if !ok {
+				return matrix, ErrIncorrectAffineValue
+			}
+			step = affineMatrix{{1, kx, 0}, {ky, 1, 0}}
+		case "rotate":
+			degrees, ok := utils.ParseFloat(value)
+			if !ok {
+				return matrix, ErrIncorrectAffineValue
+			}
+			step = rotation(degrees)
+		case "translate":
+			tx, ty, ok := pair(value, false)
+			if !ok {
+				return matrix, ErrIncorrectAffineValue
+			}
+			step = affineMatrix{{1, 0, tx}, {0, 1, ty}}
+		}
+		matrix = step.multiply(matrix)
+	}
+
+	return b.centered(matrix), nil
+}
+
+// rotation returns the matrix of rotation around the origin,
+// axis y goes down, so positive angle rotates clockwise
+func rotation(degrees float64) affineMatrix {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	return affineMatrix{{cos, -sin, 0}, {sin, cos, 0}}
+}
+
+// centered returns the transform applied around the image center instead of the top left pixel
+func (b *bmp) centered(m affineMatrix) affineMatrix {
+	centerX, centerY := float64(b.dibHeader.Width-1)/2, float64(len(b.pixelArray)-1)/2
+	return affineMatrix{{1, 0, centerX}, {0, 1, centerY}}.multiply(m.multiply(affineMatrix{{1, 0, -centerX}, {0, 1, -centerY}}))
+}
+
+// Perspective maps the quadrilateral of 4 source points to the whole image, which corrects keystone distortion
+// value format: <x1>,<y1>,<x2>,<y2>,<x3>,<y3>,<x4>,<y4>[:<options>]
+// points are the top left, top right, bottom right and bottom left corners of quadrilateral,
+// options are filter and color as of affine and size=<width>x<height> of the result, size of image by default
+// see (https://en.wikipedia.org/wiki/Homography_(computer_vision))
+func (b *bmp) Perspective(flagValue string) error {
+	pointsValue, rest := splitCanvasValue(flagValue)
+	options := map[string]string{}
+	if rest != "" {
+		var ok bool
+		if options, ok = utils.ParseOptions(rest); !ok {
+			return ErrIncorrectPerspectiveValue
+		}
+	}
+	filter, fill, err := samplingOptions(options, ErrIncorrectPerspectiveValue, "size")
+	if err != nil {
+		return err
+	}
+
+	width, height := int(b.dibHeader.Width), len(b.pixelArray)
+	if size, ok := options["size"]; ok {
+		dimensions := utils.Split(size, "x")
+		if len(dimensions) != 2 {
+			return ErrIncorrectPerspectiveValue
+		}
+		var widthOk, heightOk bool
+		width, widthOk = utils.Atoi(dimensions[0])
+		height, heightOk = utils.Atoi(dimensions[1])
+		if !widthOk || !heightOk || width < 1 || height < 1 {
+			return ErrIncorrectPerspectiveValue
+		}
+	}
+
+	values := utils.Split(pointsValue, ",")
+	if len(values) != 8 {
+		return ErrIncorrectPerspectiveValue
+	}
+	var points [4][2]float64
+	for idx, value := range values {
+		number, ok := utils.ParseFloat(value)
+		if !ok {
+			return ErrIncorrectPerspectiveValue
+		}
+		points[idx/2][idx%2] = number
+	}
+
+	// Homography maps corners of the result to the source points
+	corners := [4][2]float64{{0, 0}, {float64(width - 1), 0}, {float64(width - 1), float64(height - 1)}, {0, float64(height - 1)}}
+	h, ok := solveHomography(corners, points)
+	if !ok {
+		return ErrSingularTransform
+	}
+	b.warp(width, height, func(x, y float64) (float64, float64) {
+		w := h[6]*x + h[7]*y + 1
+		return (h[0]*x + h[1]*y + h[2]) / w, (h[3]*x + h[4]*y + h[5]) / w
+	}, filter, fill)
+	return nil
+}
+
+// solveHomography returns 8 coefficients of the projective transform mapping points from to points to,
+// the last coefficient is 1, ok is false when points are degenerate
+func solveHomography(from, to [4][2]float64) ([8]float64, bool) {
+	// Every pair of points gives two linear equations
+	system := make([][]float64, 8)
+	for idx := 0; idx < 4; idx++ {
+		x, y, u, v := from[idx][0], from[idx][1], to[idx][0], to[idx][1]
+		system[idx*2] = []float64{x, y, 1, 0, 0, 0, -x * u, -y * u, u}
+		system[idx*2+1] = []float64{0, 0, 0, x, y, 1, -x * v, -y * v, v}
+	}
+
+	var result [8]float64
+	solution, ok := solveLinear(system)
+	if !ok {
+		return result, false
+	}
+	copy(result[:], solution)
+	return result, true
+}
+
+// solveLinear solves the augmented system of linear equations by Gaussian elimination with partial pivoting
+// see (https://en.wikipedia.org/wiki/Gaussian_elimination)
+func solveLinear(system [][]float64) ([]float64, bool) {
+	size := len(system)
+	for col := 0; col < size; col++ {
+		pivot := col
+		for row := col + 1; row < size; row++ {
+			if math.Abs(system[row][col]) > math.Abs(system[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(system[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		system[col], system[pivot] = system[pivot], system[col]
+
+		for row := col + 1; row < size; row++ {
+			factor := system[row][col] / system[col][col]
+			for idx := col; idx <= size; idx++ {
+				system[row][idx] -= factor * system[col][idx]
+			}
+		}
+	}
+
+	solution := make([]float64, size)
+	for row := size - 1; row >= 0; row-- {
+		sum := system[row][size]
+		for col := row + 1; col < size; col++ {
+			sum -= system[row][col] * solution[col]
+		}
+		solution[row] = sum / system[row][row]
+	}
+	return solution, true
+}
+
+// samplingOptions returns the sampling filter and fill from options, other allowed keys are checked by the caller
+func samplingOptions(options map[string]string, errValue error, allowed ...string) (string, canvasFill, error) {
+	filter, fill := samplingFilter, canvasFill{mode: "color"}
+	for key, value := range options {
+		switch key {
+		case "filter":
+			if value != "nearest" && value != "bilinear" && value != "bicubic" {
+				return filter, fill, ErrIncorrectSamplingFilter
+			}
+			filter = value
+		case "color":
+			var err error
+			if fill, err = parseCanvasFill(value); err != nil {
+				return filter, fill, err
+			}
+		default:
+			if utils.In(key, allowed) == -1 {
+				return filter, fill, errValue
+			}
+		}
+	}
+	return filter, fill, nil
+}
+
+// warp replaces the image with the image of width and height, where every pixel is sampled from the source
+// at the point returned by mapping, coordinates go from the top left pixel
+func (b *bmp) warp(width, height int, mapping func(x, y float64) (float64, float64), filter string, fill canvasFill) {
+	source := b.pixelArray
+	sourceWidth, sourceHeight := int(b.dibHeader.Width), len(source)
+	b.setSize(uint32(width), uint32(height))
+
+	// pixel returns the source pixel in top-down coordinates, pixels outside are replaced by the fill
+	edge := canvasFill{mode: "edge"}
+	pixel := func(x, y int) []byte {
+		if fill.mode != "color" {
+			x, y = fill.index(x, sourceWidth), fill.index(y, sourceHeight)
+		} else {
+			// Color is applied to samples outside of image, so taps near the border are replicated
+			x, y = edge.index(x, sourceWidth), edge.index(y, sourceHeight)
+		}
+		return source[sourceHeight-1-y][x*3 : x*3+3]
+	}
+
+	cubic := resampleFilters["bicubic"].kernel
+	for rowIdx := 0; rowIdx < height; rowIdx++ {
+		row := b.row(rowIdx)
+		for colIdx := 0; colIdx < width; colIdx++ {
+			x, y := mapping(float64(colIdx), float64(rowIdx))
+			result := row[colIdx*3 : colIdx*3+3]
+
+			if fill.mode == "color" && (x < -0.5 || y < -0.5 || x > float64(sourceWidth)-0.5 || y > float64(sourceHeight)-0.5) {
+				copy(result, fill.color[:])
+				continue
+			}
+
+			switch filter {
+			case "nearest":
+				copy(result, pixel(int(math.Floor(x+0.5)), int(math.Floor(y+0.5))))
+			case "bilinear", "bicubic":
+				left, top := int(math.Floor(x)), int(math.Floor(y))
+				fx, fy := x-float64(left), y-float64(top)
+
+				// Taps and weights of 1D kernels
+				first, last := 0, 1
+				weight := func(offset int, fraction float64) float64 {
+					return 1 - math.Abs(float64(offset)-fraction)
+				}
+				if filter == "bicubic" {
+					first, last = -1, 2
+					weight = func(offset int, fraction float64) float64 {
+						return cubic(float64(offset) - fraction)
+					}
+				}
+
+				var sum [3]float64
+				for dy := first; dy <= last; dy++ {
+					wy := weight(dy, fy)
+					for dx := first; dx <= last; dx++ {
+						w := wy * weight(dx, fx)
+						tap := pixel(left+dx, top+dy)
+						for channel := range sum {
+							sum[channel] += w * float64(tap[channel])
+						}
+					}
+				}
+				for channel := range sum {
+					result[channel] = clampByte(sum[channel])
+				}
+			}
+		}
+	}
+}
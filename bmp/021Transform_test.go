@@ -0,0 +1,57 @@
+package bmp
+
+import "testing"
+
+func TestTransform(t *testing.T) {
+	// newGradient returns 5x5 image where blue value of pixel is its x*10 + y
+	newGradient := func() *bmp {
+		testBmp := newTestBmp(5, 5, [3]byte{})
+		for y := 0; y < 5; y++ {
+			for x := 0; x < 5; x++ {
+				testBmp.row(y)[x*3] = byte(x*10 + y)
+			}
+		}
+		return testBmp
+	}
+
+	tests := []struct {
+		name      string
+		transform func(b *bmp) error
+		// Expected blue value by x, y of the result
+		want func(x, y int) byte
+	}{
+		{"identity matrix", func(b *bmp) error { return b.Affine("1,0,0,0,1,0:filter=bicubic") }, func(x, y int) byte {
+			return byte(x*10 + y)
+		}},
+		{"translate with edge", func(b *bmp) error { return b.Affine("translate=2x0,color=edge") }, func(x, y int) byte {
+			return byte(max(x-2, 0)*10 + y)
+		}},
+		{"rotate 90", func(b *bmp) error { return b.Affine("rotate=90,filter=nearest") }, func(x, y int) byte {
+			return byte(y*10 + 4 - x)
+		}},
+		{"perspective of corners", func(b *bmp) error { return b.Perspective("0,0,4,0,4,4,0,4") }, func(x, y int) byte {
+			return byte(x*10 + y)
+		}},
+		{"perspective flip", func(b *bmp) error { return b.Perspective("4,0,0,0,0,4,4,4") }, func(x, y int) byte {
+			return byte((4-x)*10 + y)
+		}},
+	}
+
+	for _, test := range tests {
+		testBmp := newGradient()
+		if err := test.transform(testBmp); err != nil {
+			t.Fatalf("%s: error = %v", test.name, err)
+		}
+		for y := 0; y < 5; y++ {
+			for x := 0; x < 5; x++ {
+				if blue, want := testBmp.row(y)[x*3], test.want(x, y); blue != want {
+					t.Errorf("%s: pixel %d,%d = %d, want %d", test.name, x, y, blue, want)
+				}
+			}
+		}
+	}
+
+	if err := newGradient().Affine("0,0,0,0,0,0"); err != ErrSingularTransform {
+		t.Errorf("Affine() error = %v, want %v", err, ErrSingularTransform)
+	}
+}
@@ -0,0 +1,112 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectDeskewValue = errors.New("Incorrect deskew value provided, maximum angle from 1 to 45 degrees expected")
+)
+
+// Parameters of skew detection
+const (
+	deskewMaxAngle = 10
+	// Coarse search goes with the step over all angles, fine search goes around the best coarse angle
+	deskewCoarseStep = 0.5
+	deskewFineStep   = 0.05
+	// Foreground pixels are sampled evenly down to the number to keep detection fast on large scans
+	deskewSamples = 200000
+)
+
+// Deskew detects the skew angle of text lines and rotates the image back, pixels uncovered by the rotation
+// get the border color, returns the detected angle in degrees, positive angle means lines go down to the right
+// value format: empty or maximum detected angle in degrees, 10 by default
+func (b *bmp) Deskew(flagValue string) (float64, error) {
+	maxAngle := deskewMaxAngle
+	if flagValue != "" {
+		var ok bool
+		if maxAngle, ok = utils.Atoi(flagValue); !ok || maxAngle < 1 || maxAngle > 45 {
+			return 0, ErrIncorrectDeskewValue
+		}
+	}
+
+	angle := b.skewAngle(float64(maxAngle))
+	if angle == 0 {
+		return 0, nil
+	}
+
+	inverse, _ := b.centered(rotation(-angle)).invert()
+	b.warp(int(b.dibHeader.Width), len(b.pixelArray), inverse.apply, "bilinear", canvasFill{mode: "color", color: b.borderColor(trimTolerance)})
+	return angle, nil
+}
+
+// skewAngle returns the angle with the maximal variance of projection profile of foreground pixels,
+// lines of text are the sharpest peaks of profile when projected along their direction,
+// foreground is the smaller class of Otsu's binarization, so both dark text on light and light text on dark are detected
+// see (https://en.wikipedia.org/wiki/Document_layout_analysis)
+func (b *bmp) skewAngle(maxAngle float64) float64 {
+	plane := b.lumaPlane()
+	threshold := otsuThreshold(plane)
+
+	// Foreground pixels in top-down coordinates
+	var dark, light [][2]float64
+	for rowIdx, row := range plane {
+		y := float64(len(plane) - 1 - rowIdx)
+		for x, luma := range row {
+			if luma > threshold {
+				light = append(light, [2]float64{float64(x), y})
+			} else {
+				dark = append(dark, [2]float64{float64(x), y})
+			}
+		}
+	}
+	points := dark
+	if len(light) < len(dark) {
+		points = light
+	}
+	if len(points) == 0 {
+		return 0
+	}
+	if stride := len(points)/deskewSamples + 1; stride > 1 {
+		sampled := make([][2]float64, 0, len(points)/stride+1)
+		for idx := 0; idx < len(points); idx += stride {
+			sampled = append(sampled, points[idx])
+		}
+		points = sampled
+	}
+
+	// Profile is shifted by the diagonal, so rows of rotated points are never negative
+	diagonal := math.Hypot(float64(b.dibHeader.Width), float64(len(plane)))
+	profile := make([]int, int(2*diagonal)+2)
+	score := func(angle float64) float64 {
+		for idx := range profile {
+			profile[idx] = 0
+		}
+		sin, cos := math.Sincos(angle * math.Pi / 180)
+		for _, point := range points {
+			profile[int(point[1]*cos-point[0]*sin+diagonal)]++
+		}
+		sum := 0.
+		for _, count := range profile {
+			sum += float64(count * count)
+		}
+		return sum
+	}
+
+	best, bestScore := 0., score(0)
+	search := func(from, to, step float64) {
+		for angle := from; angle <= to+step/2; angle += step {
+			if value := score(angle); value > bestScore {
+				best, bestScore = angle, value
+			}
+		}
+	}
+	search(-maxAngle, maxAngle, deskewCoarseStep)
+	search(best-deskewCoarseStep, best+deskewCoarseStep, deskewFineStep)
+
+	return math.Round(best/deskewFineStep) * deskewFineStep
+}
@@ -0,0 +1,59 @@
+package bmp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDeskew(t *testing.T) {
+	tests := []struct {
+		rotate string
+		angle  float64
+	}{{"3", 3}, {"-4.5", -4.5}}
+
+	for _, test := range tests {
+		// Page with dashed lines of text
+		testBmp := newTestBmp(300, 200, [3]byte{255, 255, 255})
+		for y := 20; y < 180; y += 12 {
+			for x := 30; x < 270; x++ {
+				if x%10 < 7 {
+					copy(testBmp.row(y)[x*3:], []byte{0, 0, 0})
+					copy(testBmp.row(y + 1)[x*3:], []byte{0, 0, 0})
+				}
+			}
+		}
+		original := testBmp.Clone()
+		if err := testBmp.Affine("rotate=" + test.rotate + ",color=ffffff"); err != nil {
+			t.Fatalf("Affine() error = %v", err)
+		}
+
+		detected, err := testBmp.Deskew("")
+		if err != nil {
+			t.Fatalf("Deskew() error = %v", err)
+		}
+		if math.Abs(detected-test.angle) > 0.2 {
+			t.Errorf("Deskew() angle = %.2f, want %.2f", detected, test.angle)
+		}
+		if skew := testBmp.skewAngle(deskewMaxAngle); math.Abs(skew) > 0.2 {
+			t.Errorf("Deskew() left the angle %.2f", skew)
+		}
+
+		// Rotated back page matches the original up to the blur of dash edges, corners get the white border color
+		mismatched := 0
+		for y := 0; y < 200; y++ {
+			for x := 0; x < 300; x++ {
+				if (testBmp.row(y)[x*3] > 127) != (original.row(y)[x*3] > 127) {
+					mismatched++
+				}
+			}
+		}
+		if mismatched > 150 {
+			t.Errorf("Deskew() page differs from the original by %d pixels", mismatched)
+		}
+		for _, corner := range [][2]int{{0, 0}, {299, 0}, {0, 199}, {299, 199}} {
+			if pixel := [3]byte(testBmp.row(corner[1])[corner[0]*3:]); pixel != [3]byte{255, 255, 255} {
+				t.Errorf("Deskew() corner %v = %v, want white", corner, pixel)
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectLensValue     = errors.New("Incorrect lens value provided")
+	ErrIncorrectVignetteValue = errors.New("Incorrect vignette value provided")
+)
+
+// Default radius of vignette, the falloff reaches its strength at the corners
+const vignetteRadius = 1
+
+// Lens corrects radial distortion by the Brown model: pixel at radius r is sampled from the radius r * (1 + k1*r^2 + k2*r^4),
+// radius is normalized by the half of the smaller side of image, negative k1 corrects barrel distortion, positive k1 corrects pincushion
+// value format: k1=<k1>[,k2=<k2>,center=<x>x<y>,filter=<filter>,color=<fill>], center of image by default,
+// filter and color are the same as of affine
+// see (https://en.wikipedia.org/wiki/Distortion_(optics)#Software_correction)
+func (b *bmp) Lens(flagValue string) error {
+	options, ok := utils.ParseOptions(flagValue)
+	if !ok {
+		return ErrIncorrectLensValue
+	}
+	filter, fill, err := samplingOptions(options, ErrIncorrectLensValue, "k1", "k2", "center")
+	if err != nil {
+		return err
+	}
+
+	var k1, k2 float64
+	if options["k1"] == "" && options["k2"] == "" {
+		return ErrIncorrectLensValue
+	}
+	for key, value := range options {
+		switch key {
+		case "k1":
+			k1, ok = utils.ParseFloat(value)
+		case "k2":
+			k2, ok = utils.ParseFloat(value)
+		}
+		if !ok {
+			return ErrIncorrectLensValue
+		}
+	}
+	centerX, centerY, err := b.opticalCenter(options["center"], ErrIncorrectLensValue)
+	if err != nil {
+		return err
+	}
+
+	width, height := int(b.dibHeader.Width), len(b.pixelArray)
+	norm := float64(min(width, height)) / 2
+	b.warp(width, height, func(x, y float64) (float64, float64) {
+		dx, dy := (x-centerX)/norm, (y-centerY)/norm
+		r2 := dx*dx + dy*dy
+		factor := 1 + k1*r2 + k2*r2*r2
+		return centerX + dx*factor*norm, centerY + dy*factor*norm
+	}, filter, fill)
+	return nil
+}
+
+// Vignette darkens or brightens the image towards corners, light is multiplied by 1 - strength * (r / radius)^2 in linear light,
+// radius is normalized by the half of the diagonal, positive strength creates vignette,
+// negative strength corrects vignette of the same positive strength by dividing light by the falloff
+// value format: strength=<-1..1>[,radius=<radius>,center=<x>x<y>], strength is required, radius is 1 by default, center of image by default
+// see (https://en.wikipedia.org/wiki/Vignetting)
+func (b *bmp) Vignette(flagValue string) error {
+	options, ok := utils.ParseOptions(flagValue)
+	// Vignette without strength would leave the image unchanged
+	if !ok || options["strength"] == "" {
+		return ErrIncorrectVignetteValue
+	}
+
+	strength, radius := 0., float64(vignetteRadius)
+	for key, value := range options {
+		switch key {
+		case "strength":
+			if strength, ok = utils.ParseFloat(value); !ok || strength < -1 || strength > 1 {
+				return ErrIncorrectVignetteValue
+			}
+		case "radius":
+			if radius, ok = utils.ParseFloat(value); !ok || radius <= 0 {
+				return ErrIncorrectVignetteValue
+			}
+		case "center":
+		default:
+			return ErrIncorrectVignetteValue
+		}
+	}
+	centerX, centerY, err := b.opticalCenter(options["center"], ErrIncorrectVignetteValue)
+	if err != nil {
+		return err
+	}
+
+	width, height := int(b.dibHeader.Width), len(b.pixelArray)
+	norm := math.Hypot(float64(width), float64(height)) / 2
+	for y := 0; y < height; y++ {
+		row := b.row(y)
+		for x := 0; x < width; x++ {
+			r := math.Hypot(float64(x)-centerX, float64(y)-centerY) / norm / radius
+			// Falloff is limited, so light doesn't vanish or explode behind the radius
+			gain := max(1-math.Abs(strength)*r*r, 0.05)
+			if strength < 0 {
+				gain = 1 / gain
+			}
+			for channel := 0; channel < 3; channel++ {
+				row[x*3+channel] = linearToSrgb(srgbToLinear[row[x*3+channel]] * gain)
+			}
+		}
+	}
+	return nil
+}
+
+// opticalCenter parses the center with format <x>x<y> in pixels from the top left corner, empty value is the image center
+func (b *bmp) opticalCenter(value string, errValue error) (float64, float64, error) {
+	if value == "" {
+		return float64(b.dibHeader.Width-1) / 2, float64(len(b.pixelArray)-1) / 2, nil
+	}
+	coordinates := utils.Split(value, "x")
+	if len(coordinates) != 2 {
+		return 0, 0, errValue
+	}
+	x, xOk := utils.ParseFloat(coordinates[0])
+	y, yOk := utils.ParseFloat(coordinates[1])
+	if !xOk || !yOk {
+		return 0, 0, errValue
+	}
+	return x, y, nil
+}
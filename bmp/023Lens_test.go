@@ -0,0 +1,60 @@
+package bmp
+
+import "testing"
+
+func TestLens(t *testing.T) {
+	testBmp := newTestBmp(9, 7, [3]byte{})
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 9; x++ {
+			testBmp.row(y)[x*3] = byte(x*10 + y)
+		}
+	}
+
+	// Zero coefficients don't change the image
+	if err := testBmp.Lens("k1=0,k2=0"); err != nil {
+		t.Fatalf("Lens() error = %v", err)
+	}
+	if blue := testBmp.row(6)[8*3]; blue != 86 {
+		t.Errorf("Lens() with zero coefficients changed the corner pixel to %d", blue)
+	}
+
+	// Barrel correction samples corners closer to the center
+	if err := testBmp.Lens("k1=-0.2,filter=nearest"); err != nil {
+		t.Fatalf("Lens() error = %v", err)
+	}
+	if blue := testBmp.row(3)[4*3]; blue != 43 {
+		t.Errorf("Lens() moved the center pixel to %d", blue)
+	} else if blue := testBmp.row(6)[8*3]; blue == 86 {
+		t.Errorf("Lens() didn't move the corner pixel")
+	}
+
+	if err := testBmp.Lens("center=1x1"); err != ErrIncorrectLensValue {
+		t.Errorf("Lens() error = %v, want %v", err, ErrIncorrectLensValue)
+	}
+}
+
+func TestVignette(t *testing.T) {
+	testBmp := newTestBmp(21, 11, [3]byte{150, 150, 150})
+
+	if err := testBmp.Vignette("strength=0.5"); err != nil {
+		t.Fatalf("Vignette() error = %v", err)
+	}
+	center, corner := testBmp.row(5)[10*3], testBmp.row(0)[0]
+	if center != 150 || corner >= 150 {
+		t.Errorf("Vignette() center = %d, corner = %d, want 150 and darker", center, corner)
+	}
+
+	// Correction of the same strength restores the image
+	if err := testBmp.Vignette("strength=-0.5"); err != nil {
+		t.Fatalf("Vignette() error = %v", err)
+	}
+	if corner := testBmp.row(0)[0]; corner < 148 || corner > 152 {
+		t.Errorf("Vignette() corrected corner = %d, want 150", corner)
+	}
+
+	for _, value := range []string{"radius=1", "center=1x1", "strength=2"} {
+		if err := testBmp.Vignette(value); err != ErrIncorrectVignetteValue {
+			t.Errorf("Vignette(%q) error = %v, want %v", value, err, ErrIncorrectVignetteValue)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package bmp
+
+import (
+	"errors"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectOverlayValue = errors.New("Incorrect overlay value provided")
+)
+
+// Overlay composites the image of file onto the image, alpha channel of 32 bit overlay is respected
+// value format: <file>[:x=<x>,y=<y>,anchor=<anchor>,opacity=<0..1>,tile=<true|false>,mode=<blend mode>]
+// overlay is placed at the anchor (top-left by default) and moved by x, y away from the anchored sides,
+// tile repeats the overlay over the whole image starting from its position, mode is normal by default
+func (b *bmp) Overlay(flagValue string) error {
+	fileName, rest := splitCanvasValue(flagValue)
+	if fileName == "" {
+		return ErrIncorrectOverlayValue
+	}
+
+	x, y, anchor, opacity, tile, blend := 0, 0, canvasAnchors["top-left"], 1., false, blendModes["normal"]
+	if rest != "" {
+		options, ok := utils.ParseOptions(rest)
+		if !ok {
+			return ErrIncorrectOverlayValue
+		}
+		for key, value := range options {
+			switch key {
+			case "x":
+				x, ok = utils.Atoi(value)
+			case "y":
+				y, ok = utils.Atoi(value)
+			case "anchor":
+				anchor, ok = canvasAnchors[value]
+			case "opacity":
+				opacity, ok = utils.ParseFloat(value)
+				ok = ok && opacity >= 0 && opacity <= 1
+			case "tile":
+				tile, ok = value == "true", value == "true" || value == "false"
+			case "mode":
+				if blend, ok = blendModes[value]; !ok {
+					return ErrIncorrectBlendMode
+				}
+			default:
+				ok = false
+			}
+			if !ok {
+				return ErrIncorrectOverlayValue
+			}
+		}
+	}
+
+	layer, err := Load(fileName)
+	if err != nil {
+		return err
+	}
+
+	// Offsets move the overlay away from the anchored sides, from the center they go right and down
+	left := (int(b.dibHeader.Width) - int(layer.dibHeader.Width)) * anchor[0] / 2
+	top := (len(b.pixelArray) - len(layer.pixelArray)) * anchor[1] / 2
+	if anchor[0] == 2 {
+		x = -x
+	}
+	if anchor[1] == 2 {
+		y = -y
+	}
+
+	b.composite(layer, left+x, top+y, opacity, tile, blend)
+	return nil
+}
+
+// composite combines the layer with top left corner at x, y with the image by the blend mode
+// and mixes the result with the image by alpha of layer multiplied by opacity, tiled layer covers the whole image
+func (b *bmp) composite(layer *bmp, x, y int, opacity float64, tile bool, blend blendMode) {
+	width, height := int(b.dibHeader.Width), len(b.pixelArray)
+	layerWidth, layerHeight := int(layer.dibHeader.Width), len(layer.pixelArray)
+
+	// Covered part of image
+	left, top, right, bottom := max(x, 0), max(y, 0), min(x+layerWidth, width), min(y+layerHeight, height)
+	if tile {
+		left, top, right, bottom = 0, 0, width, height
+	}
+
+	for rowIdx := top; rowIdx < bottom; rowIdx++ {
+		row := b.row(rowIdx)
+		layerY := rowIdx - y
+		if tile {
+			layerY = (layerY%layerHeight + layerHeight) % layerHeight
+		}
+		layerRow := layer.row(layerY)
+
+		for colIdx := left; colIdx < right; colIdx++ {
+			layerX := colIdx - x
+			if tile {
+				layerX = (layerX%layerWidth + layerWidth) % layerWidth
+			}
+
+			weight := opacity
+			if layer.alpha != nil {
+				weight *= float64(layer.alpha[layerHeight-1-layerY][layerX]) / 255
+			}
+			for channel := 0; channel < 3; channel++ {
+				value := float64(row[colIdx*3+channel]) / 255
+				over := blend(value, float64(layerRow[layerX*3+channel])/255)
+				row[colIdx*3+channel] = clampByte((value + (over-value)*weight) * 255)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package bmp
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	layerFile := filepath.Join(t.TempDir(), "layer.bmp")
+	if err := newTestBmp(2, 2, [3]byte{0, 0, 255}).Save(layerFile); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	testBmp := newTestBmp(6, 6, [3]byte{255, 255, 255})
+	if err := testBmp.Overlay(layerFile + ":anchor=bottom-right,x=1,y=1,opacity=0.5"); err != nil {
+		t.Fatalf("Overlay() error = %v", err)
+	}
+	// Layer covers pixels 3-4 of rows 3-4
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 6; x++ {
+			want := [3]byte{255, 255, 255}
+			if x >= 3 && x <= 4 && y >= 3 && y <= 4 {
+				want = [3]byte{128, 128, 255}
+			}
+			if pixel := [3]byte(testBmp.row(y)[x*3 : x*3+3]); pixel != want {
+				t.Errorf("Overlay() pixel %d,%d = %v, want %v", x, y, pixel, want)
+			}
+		}
+	}
+
+	// Alpha channel of layer is multiplied by opacity, tiled layer covers the whole image
+	layer := newTestBmp(2, 1, [3]byte{0, 0, 0})
+	layer.alpha = [][]byte{{255, 0}}
+	testBmp = newTestBmp(5, 1, [3]byte{200, 200, 200})
+	testBmp.composite(layer, 0, 0, 1, true, blendModes["normal"])
+	for x, want := range []byte{0, 200, 0, 200, 0} {
+		if blue := testBmp.row(0)[x*3]; blue != want {
+			t.Errorf("composite() pixel %d = %d, want %d", x, blue, want)
+		}
+	}
+}
+
+func TestLoad32(t *testing.T) {
+	testBmp, err := Load("../samples/not-24bit.bmp")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if testBmp.GetPixelNumber() != 32 || len(testBmp.pixelArray) != 653 || len(testBmp.pixelArray[0]) != (1070*3+3)/4*4 {
+		t.Errorf("Load() of 32 bit image is not decoded to 24 bit pixel array")
+	}
+	// Alpha of sample is opaque
+	if testBmp.alpha != nil {
+		t.Errorf("Load() alpha of opaque image is not nil")
+	}
+}
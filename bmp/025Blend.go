@@ -0,0 +1,78 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+)
+
+// Errors
+var (
+	ErrIncorrectBlendMode = errors.New("Incorrect blend mode provided")
+)
+
+// Blend mode returns the channel value of base combined with the value of blend layer, values are in range [0, 1]
+// see (https://www.w3.org/TR/compositing-1/#blending)
+type blendMode func(base, blend float64) float64
+
+// Blend modes by name
+var blendModes = map[string]blendMode{
+	"normal": func(base, blend float64) float64 {
+		return blend
+	},
+	"multiply": func(base, blend float64) float64 {
+		return base * blend
+	},
+	"screen": func(base, blend float64) float64 {
+		return base + blend - base*blend
+	},
+	// Overlay is hard light with base and blend swapped
+	"overlay": func(base, blend float64) float64 {
+		return hardLight(blend, base)
+	},
+	"hard-light": hardLight,
+	"soft-light": func(base, blend float64) float64 {
+		if blend <= 0.5 {
+			return base - (1-2*blend)*base*(1-base)
+		}
+		d := math.Sqrt(base)
+		if base <= 0.25 {
+			d = ((16*base-12)*base + 4) * base
+		}
+		return base + (2*blend-1)*(d-base)
+	},
+	"difference": func(base, blend float64) float64 {
+		return math.Abs(base - blend)
+	},
+	"darken": func(base, blend float64) float64 {
+		return min(base, blend)
+	},
+	"lighten": func(base, blend float64) float64 {
+		return max(base, blend)
+	},
+	"add": func(base, blend float64) float64 {
+		return min(base+blend, 1)
+	},
+	"subtract": func(base, blend float64) float64 {
+		return max(base-blend, 0)
+	},
+}
+
+// hardLight multiplies dark values of blend layer and screens light ones
+func hardLight(base, blend float64) float64 {
+	if blend <= 0.5 {
+		return base * 2 * blend
+	}
+	return 1 - (1-base)*(1-(2*blend-1))
+}
+
+// Blend combines the layer with top left corner at x, y with the image by the blend mode,
+// the result is mixed with the image by alpha of layer multiplied by opacity
+// modes: normal, multiply, screen, overlay, soft-light, hard-light, difference, darken, lighten, add, subtract
+func (b *bmp) Blend(layer *bmp, mode string, x, y int, opacity float64) error {
+	blend, ok := blendModes[mode]
+	if !ok {
+		return ErrIncorrectBlendMode
+	}
+	b.composite(layer, x, y, opacity, false, blend)
+	return nil
+}
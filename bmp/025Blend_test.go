@@ -0,0 +1,38 @@
+package bmp
+
+import "testing"
+
+func TestBlend(t *testing.T) {
+	// Base channel value 200 and blend channel value 100
+	tests := []struct {
+		mode string
+		want byte
+	}{
+		{"normal", 100},
+		{"multiply", 78},
+		{"screen", 222},
+		{"overlay", 188},
+		{"hard-light", 157},
+		{"soft-light", 191},
+		{"difference", 100},
+		{"darken", 100},
+		{"lighten", 200},
+		{"add", 255},
+		{"subtract", 100},
+	}
+
+	for _, test := range tests {
+		testBmp := newTestBmp(3, 2, [3]byte{200, 200, 200})
+		layer := newTestBmp(3, 2, [3]byte{100, 100, 100})
+		if err := testBmp.Blend(layer, test.mode, 0, 0, 1); err != nil {
+			t.Fatalf("Blend(%s) error = %v", test.mode, err)
+		}
+		if value := testBmp.row(1)[2*3]; value != test.want {
+			t.Errorf("Blend(%s) = %d, want %d", test.mode, value, test.want)
+		}
+	}
+
+	if err := newTestBmp(1, 1, [3]byte{}).Blend(newTestBmp(1, 1, [3]byte{}), "burn", 0, 0, 1); err != ErrIncorrectBlendMode {
+		t.Errorf("Blend() error = %v, want %v", err, ErrIncorrectBlendMode)
+	}
+}
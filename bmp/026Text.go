@@ -0,0 +1,435 @@
+package bmp
+
+import (
+	"errors"
+	"os"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectTextValue = errors.New("Incorrect text value provided")
+	ErrIncorrectBdfFile   = errors.New("Font file is not in BDF format, or file is corrupted")
+)
+
+// Default parameters of text
+const (
+	textScale = 1
+	// Padding of background box in font pixels
+	textPadding = 2
+)
+
+// Options of text value
+var textOptions = []string{"x", "y", "anchor", "color", "scale", "background", "font"}
+
+// Glyph of bitmap font, rows go from the top, the leftmost pixel is the highest bit of the width
+type glyph struct {
+	width, height int
+	// Offset of the bottom left corner of bitmap from the origin on baseline, y goes up
+	xOffset, yOffset int
+	advance          int
+	rows             []uint64
+}
+
+// Bitmap font, lines are ascent + descent pixels high
+type bitmapFont struct {
+	ascent, descent int
+	glyphs          map[rune]glyph
+}
+
+// Builtin font of printable ASCII characters 8x8, public domain font8x8_basic by Daniel Hepper,
+// rows go from the top, the leftmost pixel is the lowest bit
+var font8x8 = [95][8]byte{
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // space
+	{0x18, 0x3C, 0x3C, 0x18, 0x18, 0x00, 0x18, 0x00}, // !
+	{0x36, 0x36, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // "
+	{0x36, 0x36, 0x7F, 0x36, 0x7F, 0x36, 0x36, 0x00}, // #
+	{0x0C, 0x3E, 0x03, 0x1E, 0x30, 0x1F, 0x0C, 0x00}, // $
+	{0x00, 0x63, 0x33, 0x18, 0x0C, 0x66, 0x63, 0x00}, // %
+	{0x1C, 0x36, 0x1C, 0x6E, 0x3B, 0x33, 0x6E, 0x00}, // &
+	{0x06, 0x06, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00}, // '
+	{0x18, 0x0C, 0x06, 0x06, 0x06, 0x0C, 0x18, 0x00}, // (
+	{0x06, 0x0C, 0x18, 0x18, 0x18, 0x0C, 0x06, 0x00}, // )
+	{0x00, 0x66, 0x3C, 0xFF, 0x3C, 0x66, 0x00, 0x00}, // *
+	{0x00, 0x0C, 0x0C, 0x3F, 0x0C, 0x0C, 0x00, 0x00}, // +
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x0C, 0x0C, 0x06}, // ,
+	{0x00, 0x00, 0x00, 0x3F, 0x00, 0x00, 0x00, 0x00}, // -
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x0C, 0x0C, 0x00}, // .
+	{0x60, 0x30, 0x18, 0x0C, 0x06, 0x03, 0x01, 0x00}, // /
+	{0x3E, 0x63, 0x73, 0x7B, 0x6F, 0x67, 0x3E, 0x00}, // 0
+	{0x0C, 0x0E, 0x0C, 0x0C, 0x0C, 0x0C, 0x3F, 0x00}, // 1
+	{0x1E, 0x33, 0x30, 0x1C, 0x06, 0x33, 0x3F, 0x00}, // 2
+	{0x1E, 0x33, 0x30, 0x1C, 0x30, 0x33, 0x1E, 0x00}, // 3
+	{0x38, 0x3C, 0x36, 0x33, 0x7F, 0x30, 0x78, 0x00}, // 4
+	{0x3F, 0x03, 0x1F, 0x30, 0x30, 0x33, 0x1E, 0x00}, // 5
+	{0x1C, 0x06, 0x03, 0x1F, 0x33, 0x33, 0x1E, 0x00}, // 6
+	{0x3F, 0x33, 0x30, 0x18, 0x0C, 0x0C, 0x0C, 0x00}, // 7
+	{0x1E, 0x33, 0x33, 0x1E, 0x33, 0x33, 0x1E, 0x00}, // 8
+	{0x1E, 0x33, 0x33, 0x3E, 0x30, 0x18, 0x0E, 0x00}, // 9
+	{0x00, 0x0C, 0x0C, 0x00, 0x00, 0x0C, 0x0C, 0x00}, // :
+	{0x00, 0x0C, 0x0C, 0x00, 0x00, 0x0C, 0x0C, 0x06}, // ;
+	{0x18, 0x0C, 0x06, 0x03, 0x06, 0x0C, 0x18, 0x00}, // <
+	{0x00, 0x00, 0x3F, 0x00, 0x00, 0x3F, 0x00, 0x00}, // =
+	{0x06, 0x0C, 0x18, 0x30, 0x18, 0x0C, 0x06, 0x00}, // >
+	{0x1E, 0x33, 0x30, 0x18, 0x0C, 0x00, 0x0C, 0x00}, // ?
+	{0x3E, 0x63, 0x7B, 0x7B, 0x7B, 0x03, 0x1E, 0x00}, // @
+	{0x0C, 0x1E, 0x33, 0x33, 0x3F, 0x33, 0x33, 0x00}, // A
+	{0x3F, 0x66, 0x66, 0x3E, 0x66, 0x66, 0x3F, 0x00}, // B
+	{0x3C, 0x66, 0x03, 0x03, 0x03, 0x66, 0x3C, 0x00}, // C
+	{0x1F, 0x36, 0x66, 0x66, 0x66, 0x36, 0x1F, 0x00}, // D
+	{0x7F, 0x46, 0x16, 0x1E, 0x16, 0x46, 0x7F, 0x00}, // E
+	{0x7F, 0x46, 0x16, 0x1E, 0x16, 0x06, 0x0F, 0x00}, // F
+	{0x3C, 0x66, 0x03, 0x03, 0x73, 0x66, 0x7C, 0x00}, // G
+	{0x33, 0x33, 0x33, 0x3F, 0x33, 0x33, 0x33, 0x00}, // H
+	{0x1E, 0x0C, 0x0C, 0x0C, 0x0C, 0x0C, 0x1E, 0x00}, // I
+	{0x78, 0x30, 0x30, 0x30, 0x33, 0x33, 0x1E, 0x00}, // J
+	{0x67, 0x66, 0x36, 0x1E, 0x36, 0x66, 0x67, 0x00}, // K
+	{0x0F, 0x06, 0x06, 0x06, 0x46, 0x66, 0x7F, 0x00}, // L
+	{0x63, 0x77, 0x7F, 0x7F, 0x6B, 0x63, 0x63, 0x00}, // M
+	{0x63, 0x67, 0x6F, 0x7B, 0x73, 0x63, 0x63, 0x00}, // N
+	{0x1C, 0x36, 0x63, 0x63, 0x63, 0x36, 0x1C, 0x00}, // O
+	{0x3F, 0x66, 0x66, 0x3E, 0x06, 0x06, 0x0F, 0x00}, // P
+	{0x1E, 0x33, 0x33, 0x33, 0x3B, 0x1E, 0x38, 0x00}, // Q
+	{0x3F, 0x66, 0x66, 0x3E, 0x36, 0x66, 0x67, 0x00}, // R
+	{0x1E, 0x33, 0x07, 0x0E, 0x38, 0x33, 0x1E, 0x00}, // S
+	{0x3F, 0x2D, 0x0C, 0x0C, 0x0C, 0x0C, 0x1E, 0x00}, // T
+	{0x33, 0x33, 0x33, 0x33, 0x33, 0x33, 0x3F, 0x00}, // U
+	{0x33, 0x33, 0x33, 0x33, 0x33, 0x1E, 0x0C, 0x00}, // V
+	{0x63, 0x63, 0x63, 0x6B, 0x7F, 0x77, 0x63, 0x00}, // W
+	{0x63, 0x63, 0x36, 0x1C, 0x1C, 0x36, 0x63, 0x00}, // X
+	{0x33, 0x33, 0x33, 0x1E, 0x0C, 0x0C, 0x1E, 0x00}, // Y
+	{0x7F, 0x63, 0x31, 0x18, 0x4C, 0x66, 0x7F, 0x00}, // Z
+	{0x1E, 0x06, 0x06, 0x06, 0x06, 0x06, 0x1E, 0x00}, // [
+	{0x03, 0x06, 0x0C, 0x18, 0x30, 0x60, 0x40, 0x00}, // \
+	{0x1E, 0x18, 0x18, 0x18, 0x18, 0x18, 0x1E, 0x00}, // ]
+	{0x08, 0x1C, 0x36, 0x63, 0x00, 0x00, 0x00, 0x00}, // ^
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xFF}, // _
+	{0x0C, 0x0C, 0x18, 0x00, 0x00, 0x00, 0x00, 0x00}, // `
+	{0x00, 0x00, 0x1E, 0x30, 0x3E, 0x33, 0x6E, 0x00}, // a
+	{0x07, 0x06, 0x06, 0x3E, 0x66, 0x66, 0x3B, 0x00}, // b
+	{0x00, 0x00, 0x1E, 0x33, 0x03, 0x33, 0x1E, 0x00}, // c
+	{0x38, 0x30, 0x30, 0x3E, 0x33, 0x33, 0x6E, 0x00}, // d
+	{0x00, 0x00, 0x1E, 0x33, 0x3F, 0x03, 0x1E, 0x00}, // e
+	{0x1C, 0x36, 0x06, 0x0F, 0x06, 0x06, 0x0F, 0x00}, // f
+	{0x00, 0x00, 0x6E, 0x33, 0x33, 0x3E, 0x30, 0x1F}, // g
+	{0x07, 0x06, 0x36, 0x6E, 0x66, 0x66, 0x67, 0x00}, // h
+	{0x0C, 0x00, 0x0E, 0x0C, 0x0C, 0x0C, 0x1E, 0x00}, // i
+	{0x30, 0x00, 0x30, 0x30, 0x30, 0x33, 0x33, 0x1E}, // j
+	{0x07, 0x06, 0x66, 0x36, 0x1E, 0x36, 0x67, 0x00}, // k
+	{0x0E, 0x0C, 0x0C, 0x0C, 0x0C, 0x0C, 0x1E, 0x00}, // l
+	{0x00, 0x00, 0x33, 0x7F, 0x7F, 0x6B, 0x63, 0x00}, // m
+	{0x00, 0x00, 0x1F, 0x33, 0x33, 0x33, 0x33, 0x00}, // n
+	{0x00, 0x00, 0x1E, 0x33, 0x33, 0x33, 0x1E, 0x00}, // o
+	{0x00, 0x00, 0x3B, 0x66, 0x66, 0x3E, 0x06, 0x0F}, // p
+	{0x00, 0x00, 0x6E, 0x33, 0x33, 0x3E, 0x30, 0x78}, // q
+	{0x00, 0x00, 0x3B, 0x6E, 0x66, 0x06, 0x0F, 0x00}, // r
+	{0x00, 0x00, 0x3E, 0x03, 0x1E, 0x30, 0x1F, 0x00}, // s
+	{0x08, 0x0C, 0x3E, 0x0C, 0x0C, 0x2C, 0x18, 0x00}, // t
+	{0x00, 0x00, 0x33, 0x33, 0x33, 0x33, 0x6E, 0x00}, // u
+	{0x00, 0x00, 0x33, 0x33, 0x33, 0x1E, 0x0C, 0x00}, // v
+	{0x00, 0x00, 0x63, 0x6B, 0x7F, 0x7F, 0x36, 0x00}, // w
+	{0x00, 0x00, 0x63, 0x36, 0x1C, 0x36, 0x63, 0x00}, // x
+	{0x00, 0x00, 0x33, 0x33, 0x33, 0x3E, 0x30, 0x1F}, // y
+	{0x00, 0x00, 0x3F, 0x19, 0x0C, 0x26, 0x3F, 0x00}, // z
+	{0x38, 0x0C, 0x0C, 0x07, 0x0C, 0x0C, 0x38, 0x00}, // {
+	{0x18, 0x18, 0x18, 0x00, 0x18, 0x18, 0x18, 0x00}, // |
+	{0x07, 0x0C, 0x0C, 0x38, 0x0C, 0x0C, 0x07, 0x00}, // }
+	{0x6E, 0x3B, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ~
+}
+
+// builtinFont returns the font of font8x8 glyphs, the last row is below baseline
+func builtinFont() *bitmapFont {
+	font := &bitmapFont{ascent: 7, descent: 1, glyphs: make(map[rune]glyph)}
+	for idx, bitmap := range font8x8 {
+		g := glyph{width: 8, height: 8, yOffset: -1, advance: 8, rows: make([]uint64, 8)}
+		for rowIdx, row := range bitmap {
+			// Lowest bit goes to the highest position
+			for bit := 0; bit < 8; bit++ {
+				if row&(1<<bit) != 0 {
+					g.rows[rowIdx] |= 1 << (7 - bit)
+				}
+			}
+		}
+		font.glyphs[rune(' '+idx)] = g
+	}
+	return font
+}
+
+// Text draws the text by the bitmap font, \n in text starts a new line, \\ is the backslash
+// value format: <text>[:x=<x>,y=<y>,anchor=<anchor>,color=<rrggbb>,scale=<n>,background=<rrggbb>,font=<file.bdf>]
+// options go after the last colon, so text may contain colons, e.g. time,
+// text block is placed at the anchor (top-left by default) and moved by x, y away from the anchored sides as overlay,
+// color is white by default, background draws the box behind the text, font is builtin 8x8 by default
+func (b *bmp) Text(flagValue string) error {
+	text, options := splitTextValue(flagValue)
+	if text == "" {
+		return ErrIncorrectTextValue
+	}
+
+	x, y, anchor, scale := 0, 0, canvasAnchors["top-left"], textScale
+	color, background := [3]byte{255, 255, 255}, (*[3]byte)(nil)
+	font := builtinFont()
+	for key, value := range options {
+		var ok bool
+		switch key {
+		case "x":
+			x, ok = utils.Atoi(value)
+		case "y":
+			y, ok = utils.Atoi(value)
+		case "anchor":
+			anchor, ok = canvasAnchors[value]
+		case "scale":
+			scale, ok = utils.Atoi(value)
+			ok = ok && scale >= 1
+		case "color", "background":
+			var red, green, blue byte
+			if red, green, blue, ok = utils.ParseHexColor(value); ok && key == "color" {
+				color = [3]byte{blue, green, red}
+			} else if ok {
+				background = &[3]byte{blue, green, red}
+			}
+		case "font":
+			var err error
+			if font, err = loadBdf(value); err != nil {
+				return err
+			}
+			ok = true
+		}
+		if !ok {
+			return ErrIncorrectTextValue
+		}
+	}
+
+	// Size of text block
+	lines := utils.Split(unescapeText(text), "\n")
+	lineHeight := (font.ascent + font.descent) * scale
+	blockWidth, blockHeight := 0, lineHeight*len(lines)
+	for _, line := range lines {
+		blockWidth = max(blockWidth, font.lineWidth(line)*scale)
+	}
+	padding := 0
+	if background != nil {
+		padding = textPadding * scale
+	}
+
+	// Block with padding is placed as overlay
+	left := (int(b.dibHeader.Width) - blockWidth - 2*padding) * anchor[0] / 2
+	top := (len(b.pixelArray) - blockHeight - 2*padding) * anchor[1] / 2
+	if anchor[0] == 2 {
+		x = -x
+	}
+	if anchor[1] == 2 {
+		y = -y
+	}
+	left, top = left+x, top+y
+
+	if background != nil {
+		b.fillRect(left, top, blockWidth+2*padding, blockHeight+2*padding, *background)
+	}
+	for lineIdx, line := range lines {
+		baseline := top + padding + lineIdx*lineHeight + font.ascent*scale
+		penX := left + padding
+		for _, char := range line {
+			g := font.glyph(char)
+			for rowIdx, bits := range g.rows {
+				for colIdx := 0; colIdx < g.width; colIdx++ {
+					if bits&(1<<(g.width-1-colIdx)) == 0 {
+						continue
+					}
+					// Bitmap top is yOffset + height above baseline
+					pixelX := penX + (g.xOffset+colIdx)*scale
+					pixelY := baseline - (g.yOffset+g.height-rowIdx)*scale
+					b.fillRect(pixelX, pixelY, scale, scale, color)
+				}
+			}
+			penX += g.advance * scale
+		}
+	}
+
+	return nil
+}
+
+// splitTextValue splits the value to the text and options after the last colon,
+// the part after colon is the text when it isn't the list of text options
+func splitTextValue(flagValue string) (string, map[string]string) {
+	for idx := len(flagValue) - 1; idx >= 0; idx-- {
+		if flagValue[idx] != ':' {
+			continue
+		}
+		options, ok := utils.ParseOptions(flagValue[idx+1:])
+		if !ok {
+			break
+		}
+		for key := range options {
+			if utils.In(key, textOptions) == -1 {
+				return flagValue, nil
+			}
+		}
+		return flagValue[:idx], options
+	}
+	return flagValue, nil
+}
+
+// unescapeText replaces \n with the new line and \\ with the backslash
+func unescapeText(text string) string {
+	result := []byte{}
+	for idx := 0; idx < len(text); idx++ {
+		if text[idx] == '\\' && idx+1 < len(text) && (text[idx+1] == 'n' || text[idx+1] == '\\') {
+			idx++
+			if text[idx] == 'n' {
+				result = append(result, '\n')
+				continue
+			}
+		}
+		result = append(result, text[idx])
+	}
+	return string(result)
+}
+
+// glyph returns the glyph of character, missing characters are drawn as question mark or skipped
+func (f *bitmapFont) glyph(char rune) glyph {
+	if g, ok := f.glyphs[char]; ok {
+		return g
+	}
+	return f.glyphs['?']
+}
+
+// lineWidth returns the width of line in font pixels
+func (f *bitmapFont) lineWidth(line string) int {
+	width := 0
+	for _, char := range line {
+		width += f.glyph(char).advance
+	}
+	return width
+}
+
+// fillRect fills the rectangle with top left corner at x, y by the color, parts outside of image are skipped
+func (b *bmp) fillRect(x, y, width, height int, color [3]byte) {
+	for rowIdx := max(y, 0); rowIdx < min(y+height, len(b.pixelArray)); rowIdx++ {
+		row := b.row(rowIdx)
+		for colIdx := max(x, 0); colIdx < min(x+width, int(b.dibHeader.Width)); colIdx++ {
+			copy(row[colIdx*3:colIdx*3+3], color[:])
+		}
+	}
+}
+
+// loadBdf parses the font of Glyph Bitmap Distribution Format file
+// see (https://en.wikipedia.org/wiki/Glyph_Bitmap_Distribution_Format)
+func loadBdf(fileName string) (*bitmapFont, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	font := &bitmapFont{glyphs: make(map[rune]glyph)}
+	var boundingBox []int
+	var current glyph
+	encoding, inBitmap := -1, false
+
+	// numbers parses the integer fields
+	numbers := func(fields []string) ([]int, bool) {
+		values := make([]int, len(fields))
+		for idx, field := range fields {
+			value, ok := utils.Atoi(field)
+			if !ok {
+				return nil, false
+			}
+			values[idx] = value
+		}
+		return values, true
+	}
+
+	for _, line := range utils.Split(string(content), "\n") {
+		fields := utils.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inBitmap {
+			if fields[0] == "ENDCHAR" {
+				inBitmap = false
+				if len(current.rows) != current.height {
+					return nil, ErrIncorrectBdfFile
+				}
+				if encoding >= 0 {
+					font.glyphs[rune(encoding)] = current
+				}
+				continue
+			}
+			row, ok := parseHexRow(fields[0])
+			if !ok || len(fields[0]) > 16 || len(fields[0])*4 < current.width {
+				return nil, ErrIncorrectBdfFile
+			}
+			// Rows are padded to bytes, the leftmost pixel is the highest bit of the row
+			current.rows = append(current.rows, row>>(len(fields[0])*4-current.width))
+			continue
+		}
+
+		values, ok := numbers(fields[1:])
+		switch fields[0] {
+		case "FONTBOUNDINGBOX":
+			if !ok || len(values) != 4 {
+				return nil, ErrIncorrectBdfFile
+			}
+			boundingBox = values
+		case "FONT_ASCENT", "FONT_DESCENT":
+			if !ok || len(values) != 1 {
+				return nil, ErrIncorrectBdfFile
+			}
+			if fields[0] == "FONT_ASCENT" {
+				font.ascent = values[0]
+			} else {
+				font.descent = values[0]
+			}
+		case "STARTCHAR":
+			current, encoding = glyph{}, -1
+		case "ENCODING":
+			if !ok || len(values) == 0 {
+				return nil, ErrIncorrectBdfFile
+			}
+			encoding = values[0]
+		case "DWIDTH":
+			if !ok || len(values) != 2 {
+				return nil, ErrIncorrectBdfFile
+			}
+			current.advance = values[0]
+		case "BBX":
+			if !ok || len(values) != 4 || values[0] < 0 || values[0] > 64 || values[1] < 0 {
+				return nil, ErrIncorrectBdfFile
+			}
+			current.width, current.height, current.xOffset, current.yOffset = values[0], values[1], values[2], values[3]
+		case "BITMAP":
+			inBitmap = true
+		}
+	}
+
+	// Font ascent and descent properties are optional, bounding box is used then
+	if font.ascent == 0 && font.descent == 0 && boundingBox != nil {
+		font.ascent, font.descent = boundingBox[1]+boundingBox[3], -boundingBox[3]
+	}
+	if len(font.glyphs) == 0 || font.ascent+font.descent <= 0 {
+		return nil, ErrIncorrectBdfFile
+	}
+	return font, nil
+}
+
+// parseHexRow parses the hexadecimal row of glyph bitmap
+func parseHexRow(s string) (uint64, bool) {
+	var value uint64
+	for _, char := range s {
+		var digit uint64
+		switch {
+		case char >= '0' && char <= '9':
+			digit = uint64(char - '0')
+		case char >= 'a' && char <= 'f':
+			digit = uint64(char-'a') + 10
+		case char >= 'A' && char <= 'F':
+			digit = uint64(char-'A') + 10
+		default:
+			return 0, false
+		}
+		value = value<<4 | digit
+	}
+	return value, true
+}
@@ -0,0 +1,95 @@
+package bmp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitTextValue(t *testing.T) {
+	tests := []struct {
+		value   string
+		text    string
+		options int
+	}{
+		{"label", "label", 0},
+		{"12:30", "12:30", 0},
+		{"12:30:x=5,y=5", "12:30", 2},
+		{"key:value=1", "key:value=1", 0},
+		{"a\\nb:color=ff0000", "a\\nb", 1},
+	}
+	for _, tt := range tests {
+		text, options := splitTextValue(tt.value)
+		if text != tt.text || len(options) != tt.options {
+			t.Errorf("splitTextValue(%q) = %q, %v, want %q with %d options", tt.value, text, options, tt.text, tt.options)
+		}
+	}
+	if got := unescapeText("a\\nb\\\\n"); got != "a\nb\\n" {
+		t.Errorf("unescapeText() = %q, want %q", got, "a\nb\\n")
+	}
+}
+
+func TestText(t *testing.T) {
+	// Two lines of one character with background box of padding 2 at bottom right corner
+	testBmp := newTestBmp(30, 30, [3]byte{255, 255, 255})
+	if err := testBmp.Text("_\\n_:anchor=bottom-right,x=1,y=1,color=ff0000,background=000000"); err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	// Box is 12x20 pixels with bottom right corner at 28,28
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 30; x++ {
+			want := [3]byte{255, 255, 255}
+			if x >= 17 && x <= 28 && y >= 9 && y <= 28 {
+				want = [3]byte{0, 0, 0}
+			}
+			// Underscore is the last row of glyph
+			if x >= 19 && x <= 26 && (y == 18 || y == 26) {
+				want = [3]byte{0, 0, 255}
+			}
+			if pixel := [3]byte(testBmp.row(y)[x*3 : x*3+3]); pixel != want {
+				t.Errorf("Text() pixel %d,%d = %v, want %v", x, y, pixel, want)
+			}
+		}
+	}
+
+	if err := testBmp.Text("label:scale=0"); err != ErrIncorrectTextValue {
+		t.Errorf("Text() error = %v, want %v", err, ErrIncorrectTextValue)
+	}
+}
+
+func TestLoadBdf(t *testing.T) {
+	fontFile := filepath.Join(t.TempDir(), "font.bdf")
+	content := "STARTFONT 2.1\nFONTBOUNDINGBOX 3 3 0 -1\nCHARS 1\n" +
+		"STARTCHAR A\nENCODING 65\nDWIDTH 4 0\nBBX 3 3 0 -1\nBITMAP\nE0\n40\nA0\nENDCHAR\nENDFONT\n"
+	if err := os.WriteFile(fontFile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	font, err := loadBdf(fontFile)
+	if err != nil {
+		t.Fatalf("loadBdf() error = %v", err)
+	}
+	if font.ascent != 2 || font.descent != 1 || font.glyphs['A'].advance != 4 {
+		t.Errorf("loadBdf() font = %+v, want ascent 2, descent 1 and advance 4", font)
+	}
+
+	// Glyph bitmap starts from the top of line
+	testBmp := newTestBmp(8, 3, [3]byte{0, 0, 0})
+	if err := testBmp.Text("AA:font=" + fontFile); err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	for y, want := range []string{"###.###.", ".#...#..", "#.#.#.#."} {
+		for x, char := range want {
+			if lit := testBmp.row(y)[x*3] == 255; lit != (char == '#') {
+				t.Errorf("Text() pixel %d,%d lit = %v, want %c", x, y, lit, char)
+			}
+		}
+	}
+
+	if err := os.WriteFile(fontFile, []byte("not a font"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadBdf(fontFile); err != ErrIncorrectBdfFile {
+		t.Errorf("loadBdf() error = %v, want %v", err, ErrIncorrectBdfFile)
+	}
+}
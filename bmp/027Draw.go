@@ -0,0 +1,348 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectDrawValue = errors.New("Incorrect draw value provided")
+)
+
+// Parameters of drawing
+const (
+	// Anti-aliased shapes are sampled by the grid of samples x samples per pixel
+	drawSamples = 4
+	// Head of arrow is drawn by the triangle of the length and the angle between its sides and the line
+	arrowHeadLength = 10
+	arrowHeadAngle  = 25
+)
+
+// Pen is the style of drawing primitives, color is red, green and blue,
+// width is the stroke width in pixels, fill paints the inside of closed shapes instead of their outline,
+// anti-aliasing blends the edges by the covered part of pixels
+type Pen struct {
+	Color     [3]byte
+	Width     int
+	Fill      bool
+	AntiAlias bool
+}
+
+// bgr returns the color of pen in the order of pixel array
+func (p Pen) bgr() [3]byte {
+	return [3]byte{p.Color[2], p.Color[1], p.Color[0]}
+}
+
+// Draw draws the shape of command line value
+// value format: <shape>:<coordinates>[:color=<rrggbb>,width=<width>,fill=<true|false>,aa=<true|false>], coordinates are separated by comma:
+// line and arrow: x0,y0,x1,y1; rect: x,y,width,height; circle: x,y,radius; ellipse: x,y,rx,ry; polygon: x0,y0,x1,y1,x2,y2,...
+// color is white, width is 1, fill and anti-aliasing are off by default
+func (b *bmp) Draw(flagValue string) error {
+	parts := utils.Split(flagValue, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return ErrIncorrectDrawValue
+	}
+
+	var coordinates []int
+	for _, value := range utils.Split(parts[1], ",") {
+		coordinate, ok := utils.Atoi(value)
+		if !ok {
+			return ErrIncorrectDrawValue
+		}
+		coordinates = append(coordinates, coordinate)
+	}
+
+	pen := Pen{Color: [3]byte{255, 255, 255}, Width: 1}
+	if len(parts) == 3 {
+		options, ok := utils.ParseOptions(parts[2])
+		if !ok {
+			return ErrIncorrectDrawValue
+		}
+		for key, value := range options {
+			switch key {
+			case "color":
+				var red, green, blue byte
+				red, green, blue, ok = utils.ParseHexColor(value)
+				pen.Color = [3]byte{red, green, blue}
+			case "width":
+				pen.Width, ok = utils.Atoi(value)
+				ok = ok && pen.Width >= 1
+			case "fill":
+				pen.Fill, ok = value == "true", value == "true" || value == "false"
+			case "aa":
+				pen.AntiAlias, ok = value == "true", value == "true" || value == "false"
+			default:
+				ok = false
+			}
+			if !ok {
+				return ErrIncorrectDrawValue
+			}
+		}
+	}
+
+	c := coordinates
+	switch {
+	case parts[0] == "line" && len(c) == 4:
+		b.DrawLine(c[0], c[1], c[2], c[3], pen)
+	case parts[0] == "arrow" && len(c) == 4:
+		b.DrawArrow(c[0], c[1], c[2], c[3], pen)
+	case parts[0] == "rect" && len(c) == 4 && c[2] > 0 && c[3] > 0:
+		b.DrawRect(c[0], c[1], c[2], c[3], pen)
+	case parts[0] == "circle" && len(c) == 3 && c[2] >= 0:
+		b.DrawEllipse(c[0], c[1], c[2], c[2], pen)
+	case parts[0] == "ellipse" && len(c) == 4 && c[2] >= 0 && c[3] >= 0:
+		b.DrawEllipse(c[0], c[1], c[2], c[3], pen)
+	case parts[0] == "polygon" && len(c) >= 6 && len(c)%2 == 0:
+		points := make([][2]int, len(c)/2)
+		for idx := range points {
+			points[idx] = [2]int{c[idx*2], c[idx*2+1]}
+		}
+		b.DrawPolygon(points, pen)
+	default:
+		return ErrIncorrectDrawValue
+	}
+	return nil
+}
+
+// DrawLine draws the line between the pixels, thin lines are drawn by Bresenham's algorithm
+// or by Xiaolin Wu's algorithm with anti-aliasing, wide lines get round caps
+func (b *bmp) DrawLine(x0, y0, x1, y1 int, pen Pen) {
+	if pen.Width > 1 {
+		radius := float64(pen.Width) / 2
+		b.fillShape(float64(min(x0, x1))-radius, float64(min(y0, y1))-radius, float64(max(x0, x1))+radius, float64(max(y0, y1))+radius,
+			func(x, y float64) bool {
+				return segmentDistance(x, y, float64(x0), float64(y0), float64(x1), float64(y1)) <= radius
+			}, pen)
+	} else if pen.AntiAlias {
+		b.wuLine(x0, y0, x1, y1, pen.bgr())
+	} else {
+		b.bresenhamLine(x0, y0, x1, y1, pen.bgr())
+	}
+}
+
+// DrawRect draws the rectangle with top left corner at x, y, outline is drawn inside of rectangle
+func (b *bmp) DrawRect(x, y, width, height int, pen Pen) {
+	// Pixels are centered at integer coordinates, so the rectangle covers half of pixel around them
+	left, top := float64(x)-0.5, float64(y)-0.5
+	right, bottom := left+float64(width), top+float64(height)
+	stroke := float64(pen.Width)
+	b.fillShape(left, top, right, bottom, func(px, py float64) bool {
+		inside := px >= left && px < right && py >= top && py < bottom
+		if pen.Fill {
+			return inside
+		}
+		return inside && (px < left+stroke || px >= right-stroke || py < top+stroke || py >= bottom-stroke)
+	}, pen)
+}
+
+// DrawEllipse draws the ellipse with center at x, y and radii rx, ry, circle has equal radii,
+// outline is centered on the ellipse
+func (b *bmp) DrawEllipse(x, y, rx, ry int, pen Pen) {
+	half := float64(pen.Width) / 2
+	if pen.Fill {
+		half = 0.5
+	}
+	// inEllipse checks the point against the ellipse with radii changed by delta
+	inEllipse := func(px, py, delta float64) bool {
+		radiusX, radiusY := float64(rx)+delta, float64(ry)+delta
+		if radiusX <= 0 || radiusY <= 0 {
+			return false
+		}
+		dx, dy := (px-float64(x))/radiusX, (py-float64(y))/radiusY
+		return dx*dx+dy*dy <= 1
+	}
+	b.fillShape(float64(x-rx)-half, float64(y-ry)-half, float64(x+rx)+half, float64(y+ry)+half, func(px, py float64) bool {
+		if pen.Fill {
+			return inEllipse(px, py, half)
+		}
+		return inEllipse(px, py, half) && !inEllipse(px, py, -half)
+	}, pen)
+}
+
+// DrawPolygon draws the closed polygon of points, filled polygon is painted by the even-odd rule
+func (b *bmp) DrawPolygon(points [][2]int, pen Pen) {
+	if len(points) == 0 {
+		return
+	}
+	if !pen.Fill {
+		for idx, point := range points {
+			next := points[(idx+1)%len(points)]
+			b.DrawLine(point[0], point[1], next[0], next[1], pen)
+		}
+		return
+	}
+
+	vertices := make([][2]float64, len(points))
+	left, top, right, bottom := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for idx, point := range points {
+		vertices[idx] = [2]float64{float64(point[0]), float64(point[1])}
+		left, top = min(left, vertices[idx][0]), min(top, vertices[idx][1])
+		right, bottom = max(right, vertices[idx][0]), max(bottom, vertices[idx][1])
+	}
+	b.fillShape(left, top, right, bottom, func(x, y float64) bool {
+		return insidePolygon(vertices, x, y)
+	}, pen)
+}
+
+// DrawArrow draws the line from x0, y0 with the filled head at x1, y1
+func (b *bmp) DrawArrow(x0, y0, x1, y1 int, pen Pen) {
+	length := math.Hypot(float64(x1-x0), float64(y1-y0))
+	if length == 0 {
+		return
+	}
+	headLength := float64(arrowHeadLength + 3*pen.Width)
+	dx, dy := float64(x1-x0)/length, float64(y1-y0)/length
+
+	// Line ends inside of the head, so its caps don't stick out of the tip
+	baseX, baseY := float64(x1)-dx*headLength/2, float64(y1)-dy*headLength/2
+	b.DrawLine(x0, y0, int(math.Round(baseX)), int(math.Round(baseY)), pen)
+
+	sin, cos := math.Sincos(arrowHeadAngle * math.Pi / 180)
+	head := [][2]float64{{float64(x1), float64(y1)}}
+	for _, side := range []float64{1, -1} {
+		// Direction back from the tip rotated by the head angle to each side
+		sideX, sideY := -dx*cos-side*dy*sin, -dy*cos+side*dx*sin
+		head = append(head, [2]float64{float64(x1) + sideX*headLength, float64(y1) + sideY*headLength})
+	}
+	b.fillShape(min(head[0][0], head[1][0], head[2][0]), min(head[0][1], head[1][1], head[2][1]),
+		max(head[0][0], head[1][0], head[2][0]), max(head[0][1], head[1][1], head[2][1]),
+		func(x, y float64) bool {
+			return insidePolygon(head, x, y)
+		}, pen)
+}
+
+// bresenhamLine draws the line of one pixel width
+// see (https://en.wikipedia.org/wiki/Bresenham%27s_line_algorithm)
+func (b *bmp) bresenhamLine(x0, y0, x1, y1 int, color [3]byte) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	stepX, stepY := 1, 1
+	if x0 > x1 {
+		stepX = -1
+	}
+	if y0 > y1 {
+		stepY = -1
+	}
+	err := dx + dy
+	for {
+		b.plot(x0, y0, color, 1)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		doubled := 2 * err
+		if doubled >= dy {
+			err += dy
+			x0 += stepX
+		}
+		if doubled <= dx {
+			err += dx
+			y0 += stepY
+		}
+	}
+}
+
+// wuLine draws the anti-aliased line of one pixel width, every step along the major axis
+// splits the intensity between two pixels by the distance to the ideal line
+// see (https://en.wikipedia.org/wiki/Xiaolin_Wu%27s_line_algorithm)
+func (b *bmp) wuLine(x0, y0, x1, y1 int, color [3]byte) {
+	steep := abs(y1-y0) > abs(x1-x0)
+	if steep {
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if x0 > x1 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+
+	gradient := 1.
+	if x1 != x0 {
+		gradient = float64(y1-y0) / float64(x1-x0)
+	}
+	y := float64(y0)
+	for x := x0; x <= x1; x++ {
+		base := math.Floor(y)
+		fraction := y - base
+		if steep {
+			b.plot(int(base), x, color, 1-fraction)
+			b.plot(int(base)+1, x, color, fraction)
+		} else {
+			b.plot(x, int(base), color, 1-fraction)
+			b.plot(x, int(base)+1, color, fraction)
+		}
+		y += gradient
+	}
+}
+
+// fillShape paints the pixels of bounding box which centers are inside of the shape,
+// anti-aliased pen paints pixels by the part of samples inside of the shape
+func (b *bmp) fillShape(left, top, right, bottom float64, inside func(x, y float64) bool, pen Pen) {
+	color := pen.bgr()
+	fromX, toX := max(int(math.Floor(left)), 0), min(int(math.Ceil(right)), int(b.dibHeader.Width)-1)
+	fromY, toY := max(int(math.Floor(top)), 0), min(int(math.Ceil(bottom)), len(b.pixelArray)-1)
+
+	for y := fromY; y <= toY; y++ {
+		for x := fromX; x <= toX; x++ {
+			if !pen.AntiAlias {
+				if inside(float64(x), float64(y)) {
+					b.plot(x, y, color, 1)
+				}
+				continue
+			}
+			count := 0
+			for sampleY := 0; sampleY < drawSamples; sampleY++ {
+				for sampleX := 0; sampleX < drawSamples; sampleX++ {
+					offsetX := (float64(sampleX)+0.5)/drawSamples - 0.5
+					offsetY := (float64(sampleY)+0.5)/drawSamples - 0.5
+					if inside(float64(x)+offsetX, float64(y)+offsetY) {
+						count++
+					}
+				}
+			}
+			b.plot(x, y, color, float64(count)/(drawSamples*drawSamples))
+		}
+	}
+}
+
+// plot mixes the pixel with the color by the coverage from 0 to 1, pixels outside of image are skipped
+func (b *bmp) plot(x, y int, color [3]byte, coverage float64) {
+	if x < 0 || y < 0 || x >= int(b.dibHeader.Width) || y >= len(b.pixelArray) || coverage <= 0 {
+		return
+	}
+	row := b.row(y)
+	for channel := 0; channel < 3; channel++ {
+		value := float64(row[x*3+channel])
+		row[x*3+channel] = clampByte(value + (float64(color[channel])-value)*min(coverage, 1))
+	}
+}
+
+// abs returns the absolute value of integer
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// segmentDistance returns the distance from the point to the segment
+func segmentDistance(x, y, x0, y0, x1, y1 float64) float64 {
+	dx, dy := x1-x0, y1-y0
+	t := 0.
+	if length := dx*dx + dy*dy; length > 0 {
+		t = max(0, min(1, ((x-x0)*dx+(y-y0)*dy)/length))
+	}
+	return math.Hypot(x-x0-t*dx, y-y0-t*dy)
+}
+
+// insidePolygon checks the point by the even-odd rule, the ray to the right crosses the edges odd number of times
+// see (https://en.wikipedia.org/wiki/Even%E2%80%93odd_rule)
+func insidePolygon(vertices [][2]float64, x, y float64) bool {
+	inside := false
+	for idx, prev := 0, len(vertices)-1; idx < len(vertices); prev, idx = idx, idx+1 {
+		a, c := vertices[idx], vertices[prev]
+		if (a[1] > y) != (c[1] > y) && x < (c[0]-a[0])*(y-a[1])/(c[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
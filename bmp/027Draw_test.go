@@ -0,0 +1,76 @@
+package bmp
+
+import "testing"
+
+func TestDrawLine(t *testing.T) {
+	testBmp := newTestBmp(8, 8, [3]byte{0, 0, 0})
+	testBmp.DrawLine(0, 0, 6, 2, Pen{Color: [3]byte{0, 0, 255}, Width: 1})
+	lit := litPixels(testBmp)
+	// One pixel per column of the gentle line
+	want := [][2]int{{0, 0}, {1, 0}, {2, 1}, {3, 1}, {4, 1}, {5, 2}, {6, 2}}
+	if len(lit) != len(want) {
+		t.Errorf("DrawLine() lit %d pixels, want %d", len(lit), len(want))
+	}
+	for _, pixel := range want {
+		if lit[pixel] != 255 {
+			t.Errorf("DrawLine() pixel %v is not drawn", pixel)
+		}
+	}
+
+	// Anti-aliased line splits intensity between two pixels of the column
+	testBmp = newTestBmp(8, 8, [3]byte{0, 0, 0})
+	testBmp.DrawLine(0, 0, 4, 2, Pen{Color: [3]byte{0, 0, 255}, Width: 1, AntiAlias: true})
+	lit = litPixels(testBmp)
+	if lit[[2]int{1, 0}] != 128 || lit[[2]int{1, 1}] != 128 || lit[[2]int{2, 1}] != 255 {
+		t.Errorf("DrawLine() anti-aliased pixels = %v", lit)
+	}
+}
+
+func TestDrawShapes(t *testing.T) {
+	pen := Pen{Color: [3]byte{0, 0, 255}, Width: 1}
+
+	// Outline of rectangle 4x3 has 10 pixels
+	testBmp := newTestBmp(8, 8, [3]byte{0, 0, 0})
+	testBmp.DrawRect(1, 1, 4, 3, pen)
+	if lit := litPixels(testBmp); len(lit) != 10 || lit[[2]int{2, 2}] != 0 {
+		t.Errorf("DrawRect() lit %d pixels, want 10 without the center", len(lit))
+	}
+
+	// Filled circle of radius 2 is the square 5x5 without corners
+	testBmp = newTestBmp(8, 8, [3]byte{0, 0, 0})
+	testBmp.DrawEllipse(3, 3, 2, 2, Pen{Color: pen.Color, Width: 1, Fill: true})
+	if lit := litPixels(testBmp); len(lit) != 21 {
+		t.Errorf("DrawEllipse() lit %d pixels, want 21", len(lit))
+	}
+
+	// Filled triangle covers the half of square
+	testBmp = newTestBmp(12, 12, [3]byte{0, 0, 0})
+	testBmp.DrawPolygon([][2]int{{0, 0}, {10, 0}, {0, 10}}, Pen{Color: pen.Color, Width: 1, Fill: true})
+	if lit := litPixels(testBmp); len(lit) != 55 {
+		t.Errorf("DrawPolygon() lit %d pixels, want 55", len(lit))
+	}
+
+	// Head of arrow is wider than the line
+	testBmp = newTestBmp(30, 11, [3]byte{0, 0, 0})
+	testBmp.DrawArrow(0, 5, 29, 5, pen)
+	lit := litPixels(testBmp)
+	if lit[[2]int{5, 4}] != 0 || lit[[2]int{24, 3}] == 0 || lit[[2]int{28, 5}] == 0 {
+		t.Errorf("DrawArrow() pixels = %v", lit)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	testBmp := newTestBmp(8, 8, [3]byte{0, 0, 0})
+	if err := testBmp.Draw("rect:0,0,8,8:color=0000ff,fill=true"); err != nil {
+		t.Fatalf("Draw() error = %v", err)
+	}
+	if lit := litPixels(testBmp); len(lit) != 64 {
+		t.Errorf("Draw() lit %d pixels, want 64", len(lit))
+	}
+
+	for _, value := range []string{"rect:0,0,8", "circle:1,1,-1", "line:0,0,1,1:width=0", "star:1,1,1", "polygon:0,0,1,1"} {
+		if err := testBmp.Draw(value); err != ErrIncorrectDrawValue {
+			t.Errorf("Draw(%q) error = %v, want %v", value, err, ErrIncorrectDrawValue)
+		}
+	}
+}
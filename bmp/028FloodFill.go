@@ -0,0 +1,142 @@
+package bmp
+
+import (
+	"errors"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectFloodFillValue = errors.New("Incorrect flood fill value provided")
+	ErrIncorrectSelection      = errors.New("Incorrect selection: seed must be inside of image, tolerance from 0 to 255 and connectivity 4 or 8")
+)
+
+// Default difference of channel value from the seed color which is still filled
+const floodTolerance = 10
+
+// FloodFill fills the region connected to the seed pixel by the color
+// value format: <x>,<y>[:color=<rrggbb>,tolerance=<0..255>,connectivity=<4|8>], color is white, tolerance is 10 and connectivity is 4 by default
+func (b *bmp) FloodFill(flagValue string) error {
+	seed, rest := splitCanvasValue(flagValue)
+	coordinates := utils.Split(seed, ",")
+	if len(coordinates) != 2 {
+		return ErrIncorrectFloodFillValue
+	}
+	x, xOk := utils.Atoi(coordinates[0])
+	y, yOk := utils.Atoi(coordinates[1])
+	if !xOk || !yOk {
+		return ErrIncorrectFloodFillValue
+	}
+
+	color, tolerance, connectivity := [3]byte{255, 255, 255}, floodTolerance, 4
+	if rest != "" {
+		options, ok := utils.ParseOptions(rest)
+		if !ok {
+			return ErrIncorrectFloodFillValue
+		}
+		for key, value := range options {
+			switch key {
+			case "color":
+				var red, green, blue byte
+				red, green, blue, ok = utils.ParseHexColor(value)
+				color = [3]byte{blue, green, red}
+			case "tolerance":
+				tolerance, ok = utils.Atoi(value)
+			case "connectivity":
+				connectivity, ok = utils.Atoi(value)
+			default:
+				ok = false
+			}
+			if !ok {
+				return ErrIncorrectFloodFillValue
+			}
+		}
+	}
+
+	mask, err := b.Select(x, y, tolerance, connectivity)
+	if err != nil {
+		return err
+	}
+	for rowIdx, maskRow := range mask {
+		row := b.row(rowIdx)
+		for colIdx, selected := range maskRow {
+			if selected != 0 {
+				copy(row[colIdx*3:colIdx*3+3], color[:])
+			}
+		}
+	}
+	return nil
+}
+
+// Select returns the mask of region connected to the seed pixel like the magic wand,
+// pixels of region differ from the seed color by no more than tolerance in every channel,
+// connectivity 4 joins pixels by sides, 8 joins them by corners too,
+// rows of mask go from the top, selected pixels are 255, other ones are 0
+// see (https://en.wikipedia.org/wiki/Flood_fill#Span_filling)
+func (b *bmp) Select(x, y, tolerance, connectivity int) ([][]byte, error) {
+	width, height := int(b.dibHeader.Width), len(b.pixelArray)
+	if x < 0 || y < 0 || x >= width || y >= height || tolerance < 0 || tolerance > 255 || (connectivity != 4 && connectivity != 8) {
+		return nil, ErrIncorrectSelection
+	}
+
+	mask := make([][]byte, height)
+	for idx := range mask {
+		mask[idx] = make([]byte, width)
+	}
+	seedColor := [3]byte(b.row(y)[x*3 : x*3+3])
+	matches := func(row []byte, colIdx int) bool {
+		for channel := 0; channel < 3; channel++ {
+			if absDiff(row[colIdx*3+channel], seedColor[channel]) > tolerance {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Every seed fills the whole span of its row, then spans of neighbour rows under it are seeded
+	seeds := [][2]int{{x, y}}
+	for len(seeds) > 0 {
+		seed := seeds[len(seeds)-1]
+		seeds = seeds[:len(seeds)-1]
+		row, maskRow := b.row(seed[1]), mask[seed[1]]
+		if maskRow[seed[0]] != 0 {
+			continue
+		}
+
+		left, right := seed[0], seed[0]
+		for left > 0 && maskRow[left-1] == 0 && matches(row, left-1) {
+			left--
+		}
+		for right < width-1 && maskRow[right+1] == 0 && matches(row, right+1) {
+			right++
+		}
+		for colIdx := left; colIdx <= right; colIdx++ {
+			maskRow[colIdx] = 255
+		}
+
+		// Diagonal neighbours extend the span by a pixel
+		from, to := left, right
+		if connectivity == 8 {
+			from, to = max(left-1, 0), min(right+1, width-1)
+		}
+		for _, rowIdx := range []int{seed[1] - 1, seed[1] + 1} {
+			if rowIdx < 0 || rowIdx >= height {
+				continue
+			}
+			neighbour, neighbourMask := b.row(rowIdx), mask[rowIdx]
+			inSpan := false
+			for colIdx := from; colIdx <= to; colIdx++ {
+				if neighbourMask[colIdx] == 0 && matches(neighbour, colIdx) {
+					if !inSpan {
+						seeds = append(seeds, [2]int{colIdx, rowIdx})
+					}
+					inSpan = true
+				} else {
+					inSpan = false
+				}
+			}
+		}
+	}
+	return mask, nil
+}
@@ -0,0 +1,68 @@
+package bmp
+
+import "testing"
+
+func TestSelect(t *testing.T) {
+	// Diagonal wall of dark pixels splits the image, its pixels touch by corners only
+	testBmp := newTestBmp(5, 5, [3]byte{200, 200, 200})
+	for idx := 0; idx < 5; idx++ {
+		copy(testBmp.row(idx)[(4-idx)*3:(4-idx)*3+3], []byte{0, 0, 0})
+	}
+	// Noise within tolerance stays in the region
+	testBmp.row(0)[0] = 205
+
+	mask, err := testBmp.Select(0, 0, 10, 4)
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	count := 0
+	for y, row := range mask {
+		for x, value := range row {
+			if value == 255 {
+				count++
+				if x+y >= 4 {
+					t.Errorf("Select() pixel %d,%d behind the wall is selected", x, y)
+				}
+			}
+		}
+	}
+	if count != 10 {
+		t.Errorf("Select() selected %d pixels, want 10", count)
+	}
+
+	// Wall pixels are joined by corners with 8 connectivity
+	mask, _ = testBmp.Select(4, 0, 0, 8)
+	for idx := 0; idx < 5; idx++ {
+		if mask[idx][4-idx] != 255 || mask[idx][(5-idx)%5] != 0 {
+			t.Errorf("Select() with 8 connectivity row %d = %v", idx, mask[idx])
+		}
+	}
+
+	if _, err := testBmp.Select(5, 0, 10, 4); err != ErrIncorrectSelection {
+		t.Errorf("Select() error = %v, want %v", err, ErrIncorrectSelection)
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	// Frame of ring is left untouched by the fill of inside
+	testBmp := newTestBmp(5, 5, [3]byte{0, 0, 0})
+	testBmp.DrawRect(0, 0, 5, 5, Pen{Color: [3]byte{255, 255, 255}, Width: 1})
+	if err := testBmp.FloodFill("2,2:color=ff0000,tolerance=0"); err != nil {
+		t.Fatalf("FloodFill() error = %v", err)
+	}
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			want := [3]byte{255, 255, 255}
+			if x >= 1 && x <= 3 && y >= 1 && y <= 3 {
+				want = [3]byte{0, 0, 255}
+			}
+			if pixel := [3]byte(testBmp.row(y)[x*3 : x*3+3]); pixel != want {
+				t.Errorf("FloodFill() pixel %d,%d = %v, want %v", x, y, pixel, want)
+			}
+		}
+	}
+
+	if err := testBmp.FloodFill("2:color=ff0000"); err != ErrIncorrectFloodFillValue {
+		t.Errorf("FloodFill() error = %v, want %v", err, ErrIncorrectFloodFillValue)
+	}
+}
@@ -0,0 +1,239 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectRegionValue = errors.New("Incorrect region value provided")
+	ErrRegionSizeChanged    = errors.New("Operation changes the size of image and can't be restricted to region")
+)
+
+// Region returns the mask of region which restricts operations, rows of mask go from the top,
+// pixels of region are 255, pixels outside of region are 0, feathered edges have values between them
+// value format: <shape>:<parameters>[:feather=<pixels>,invert=<true|false>] or none which returns the nil mask:
+// rect:x,y,width,height; ellipse:x,y,rx,ry; mask:<file> of grayscale BMP, stretched to the image size;
+// wand:x,y selects as Select with options tolerance and connectivity, 10 and 4 by default
+func (b *bmp) Region(flagValue string) ([][]byte, error) {
+	if flagValue == "none" {
+		return nil, nil
+	}
+	shape, rest := splitCanvasValue(flagValue)
+	parameters, rest := splitCanvasValue(rest)
+	if parameters == "" {
+		return nil, ErrIncorrectRegionValue
+	}
+
+	feather, invert, tolerance, connectivity := 0, false, floodTolerance, 4
+	if rest != "" {
+		options, ok := utils.ParseOptions(rest)
+		if !ok {
+			return nil, ErrIncorrectRegionValue
+		}
+		for key, value := range options {
+			switch key {
+			case "feather":
+				feather, ok = utils.Atoi(value)
+				ok = ok && feather >= 0
+			case "invert":
+				invert, ok = value == "true", value == "true" || value == "false"
+			case "tolerance":
+				tolerance, ok = utils.Atoi(value)
+				ok = ok && shape == "wand"
+			case "connectivity":
+				connectivity, ok = utils.Atoi(value)
+				ok = ok && shape == "wand"
+			default:
+				ok = false
+			}
+			if !ok {
+				return nil, ErrIncorrectRegionValue
+			}
+		}
+	}
+
+	var mask [][]byte
+	var err error
+	switch shape {
+	case "rect", "ellipse", "wand":
+		mask, err = b.shapeMask(shape, parameters, tolerance, connectivity)
+	case "mask":
+		mask, err = b.fileMask(parameters)
+	default:
+		err = ErrIncorrectRegionValue
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if feather > 0 {
+		featherMask(mask, feather)
+	}
+	if invert {
+		for _, row := range mask {
+			for idx := range row {
+				row[idx] = 255 - row[idx]
+			}
+		}
+	}
+	return mask, nil
+}
+
+// shapeMask returns the mask of rectangle, ellipse or magic wand selection by its coordinates
+func (b *bmp) shapeMask(shape, parameters string, tolerance, connectivity int) ([][]byte, error) {
+	var c []int
+	for _, value := range utils.Split(parameters, ",") {
+		coordinate, ok := utils.Atoi(value)
+		if !ok {
+			return nil, ErrIncorrectRegionValue
+		}
+		c = append(c, coordinate)
+	}
+	if shape == "wand" {
+		if len(c) != 2 {
+			return nil, ErrIncorrectRegionValue
+		}
+		return b.Select(c[0], c[1], tolerance, connectivity)
+	}
+	if len(c) != 4 || c[2] <= 0 || c[3] <= 0 {
+		return nil, ErrIncorrectRegionValue
+	}
+
+	width, height := int(b.dibHeader.Width), len(b.pixelArray)
+	mask := make([][]byte, height)
+	for y := range mask {
+		mask[y] = make([]byte, width)
+		for x := range mask[y] {
+			inside := x >= c[0] && x < c[0]+c[2] && y >= c[1] && y < c[1]+c[3]
+			if shape == "ellipse" {
+				// Half of pixel is added to radii as of filled ellipse of draw
+				dx, dy := float64(x-c[0])/(float64(c[2])+0.5), float64(y-c[1])/(float64(c[3])+0.5)
+				inside = dx*dx+dy*dy <= 1
+			}
+			if inside {
+				mask[y][x] = 255
+			}
+		}
+	}
+	return mask, nil
+}
+
+// fileMask returns the mask of luma of BMP file stretched to the image size
+func (b *bmp) fileMask(fileName string) ([][]byte, error) {
+	maskBmp, err := Load(fileName)
+	if err != nil {
+		return nil, err
+	}
+	width, height := int(b.dibHeader.Width), len(b.pixelArray)
+	if int(maskBmp.dibHeader.Width) != width || len(maskBmp.pixelArray) != height {
+		if err := maskBmp.resample(width, height, "bilinear"); err != nil {
+			return nil, err
+		}
+	}
+
+	// Plane of luma goes in the order of pixel array
+	plane := maskBmp.lumaPlane()
+	mask := make([][]byte, height)
+	for y := range mask {
+		mask[y] = plane[height-1-y]
+	}
+	return mask, nil
+}
+
+// featherMask softens edges of mask by the Gaussian blur, radius is the distance where the edge fades,
+// blur goes by rows and then by columns, the sum of kernel is normalized at image borders
+// see (https://en.wikipedia.org/wiki/Gaussian_blur)
+func featherMask(mask [][]byte, radius int) {
+	height, width := len(mask), len(mask[0])
+	// Weights beyond the mask are never used, so the kernel is limited to the mask size
+	sigma := float64(radius) / 3
+	radius = min(radius, max(width, height))
+	kernel := make([]float64, 2*radius+1)
+	for idx := range kernel {
+		offset := float64(idx - radius)
+		kernel[idx] = math.Exp(-offset * offset / (2 * sigma * sigma))
+	}
+
+	values := make([][]float64, height)
+	for y := range values {
+		values[y] = make([]float64, width)
+		for x := range values[y] {
+			values[y][x] = float64(mask[y][x])
+		}
+	}
+
+	// blur convolves the line of length by the kernel, get and set access the line
+	blur := func(length int, get func(idx int) float64, set func(idx int, value float64)) {
+		line := make([]float64, length)
+		for idx := range line {
+			line[idx] = get(idx)
+		}
+		for idx := range line {
+			sum, weights := 0., 0.
+			for offset, weight := range kernel {
+				if pos := idx + offset - radius; pos >= 0 && pos < length {
+					sum += line[pos] * weight
+					weights += weight
+				}
+			}
+			set(idx, sum/weights)
+		}
+	}
+	for y := 0; y < height; y++ {
+		blur(width, func(x int) float64 { return values[y][x] }, func(x int, value float64) { values[y][x] = value })
+	}
+	for x := 0; x < width; x++ {
+		blur(height, func(y int) float64 { return values[y][x] }, func(y int, value float64) { values[y][x] = value })
+	}
+
+	for y := range mask {
+		for x := range mask[y] {
+			mask[y][x] = clampByte(values[y][x])
+		}
+	}
+}
+
+// Clone returns the copy of image, so it can be compared with the image after an operation
+func (b *bmp) Clone() *bmp {
+	clone := *b
+	fileHeader, dibHeader := *b.fileHeader, *b.dibHeader
+	clone.fileHeader, clone.dibHeader = &fileHeader, &dibHeader
+	clone.pixelArray = make([][]byte, len(b.pixelArray))
+	for idx, row := range b.pixelArray {
+		clone.pixelArray[idx] = append([]byte(nil), row...)
+	}
+	if b.alpha != nil {
+		clone.alpha = make([][]byte, len(b.alpha))
+		for idx, row := range b.alpha {
+			clone.alpha[idx] = append([]byte(nil), row...)
+		}
+	}
+	return &clone
+}
+
+// Restrict keeps the result of operation only inside of region: pixels are mixed with the pixels of original image
+// before the operation by the mask of region, operations changing the size of image can't be restricted
+func (b *bmp) Restrict(original *bmp, mask [][]byte) error {
+	width, height := int(b.dibHeader.Width), len(b.pixelArray)
+	if width != int(original.dibHeader.Width) || height != len(original.pixelArray) || len(mask) != height || len(mask[0]) != width {
+		return ErrRegionSizeChanged
+	}
+
+	for y, maskRow := range mask {
+		row, originalRow := b.row(y), original.row(y)
+		for x, weight := range maskRow {
+			if weight == 255 {
+				continue
+			}
+			for channel := x * 3; channel < x*3+3; channel++ {
+				value, originalValue := float64(row[channel]), float64(originalRow[channel])
+				row[channel] = clampByte(originalValue + (value-originalValue)*float64(weight)/255)
+			}
+		}
+	}
+	return nil
+}
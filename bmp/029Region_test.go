@@ -0,0 +1,95 @@
+package bmp
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRegion(t *testing.T) {
+	testBmp := newTestBmp(6, 4, [3]byte{0, 0, 0})
+	mask, err := testBmp.Region("rect:1,1,2,2")
+	if err != nil {
+		t.Fatalf("Region() error = %v", err)
+	}
+	for y, row := range mask {
+		for x, value := range row {
+			want := byte(0)
+			if x >= 1 && x <= 2 && y >= 1 && y <= 2 {
+				want = 255
+			}
+			if value != want {
+				t.Errorf("Region() pixel %d,%d = %d, want %d", x, y, value, want)
+			}
+		}
+	}
+
+	// Inverted mask of file is stretched to the image size
+	maskFile := filepath.Join(t.TempDir(), "mask.bmp")
+	maskBmp := newTestBmp(3, 2, [3]byte{0, 0, 0})
+	copy(maskBmp.row(0)[:3], []byte{255, 255, 255})
+	if err := maskBmp.Save(maskFile); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	mask, err = testBmp.Region("mask:" + maskFile + ":invert=true")
+	if err != nil {
+		t.Fatalf("Region() error = %v", err)
+	}
+	if len(mask) != 4 || len(mask[0]) != 6 || mask[0][0] != 0 || mask[3][5] != 255 {
+		t.Errorf("Region() of mask file = %v", mask)
+	}
+
+	// Feathered edge goes down smoothly
+	testBmp = newTestBmp(20, 1, [3]byte{0, 0, 0})
+	mask, _ = testBmp.Region("rect:0,0,10,1:feather=4")
+	for x := 1; x < 20; x++ {
+		if mask[0][x] > mask[0][x-1] {
+			t.Errorf("Region() feathered mask goes up at %d: %v", x, mask[0])
+		}
+	}
+	if mask[0][0] != 255 || mask[0][19] != 0 || mask[0][9] <= 100 || mask[0][9] >= 200 {
+		t.Errorf("Region() feathered mask = %v", mask[0])
+	}
+
+	// Huge feather spreads the half of line over the whole mask evenly
+	mask, err = testBmp.Region("rect:0,0,10,1:feather=100000000000")
+	if err != nil {
+		t.Fatalf("Region() error = %v", err)
+	}
+	for x, value := range mask[0] {
+		if value < 126 || value > 129 {
+			t.Errorf("Region() mask with huge feather at %d = %d, want about 128", x, value)
+		}
+	}
+
+	for _, value := range []string{"rect:0,0,5", "star:1,1", "ellipse:1,1,0,2", "rect:0,0,2,2:tolerance=5", "wand:9,9"} {
+		if _, err := testBmp.Region(value); err == nil {
+			t.Errorf("Region(%q) error is nil", value)
+		}
+	}
+}
+
+func TestRestrict(t *testing.T) {
+	testBmp := newTestBmp(4, 1, [3]byte{0, 0, 0})
+	original := testBmp.Clone()
+	if err := testBmp.Filter("negative"); err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	// Clone isn't changed by the operation
+	if original.row(0)[0] != 0 {
+		t.Fatalf("Clone() shares pixels with the image")
+	}
+
+	if err := testBmp.Restrict(original, [][]byte{{255, 0, 128, 0}}); err != nil {
+		t.Fatalf("Restrict() error = %v", err)
+	}
+	for x, want := range []byte{255, 0, 128, 0} {
+		if pixel := testBmp.row(0)[x*3]; pixel != want {
+			t.Errorf("Restrict() pixel %d = %d, want %d", x, pixel, want)
+		}
+	}
+
+	testBmp.setSize(2, 1)
+	if err := testBmp.Restrict(original, [][]byte{{255, 0, 128, 0}}); err != ErrRegionSizeChanged {
+		t.Errorf("Restrict() error = %v, want %v", err, ErrRegionSizeChanged)
+	}
+}
@@ -0,0 +1,243 @@
+package bmp
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"bitmap/utils"
+)
+
+// Errors
+var (
+	ErrIncorrectImageSize    = errors.New("Incorrect size of image provided, width and height must be positive")
+	ErrIncorrectPatternValue = errors.New("Incorrect pattern value provided")
+)
+
+// Default parameters of patterns
+const (
+	patternCellSize = 16
+	perlinScale     = 32
+	perlinOctaves   = 4
+	// Resolution of new images in pixels per meter, 72 DPI
+	newResolution = 2835
+)
+
+// Options of patterns by their names
+var patternOptions = map[string][]string{
+	"solid":   {"colors"},
+	"linear":  {"colors", "angle"},
+	"radial":  {"colors", "center", "radius"},
+	"conic":   {"colors", "center", "angle"},
+	"checker": {"colors", "size"},
+	"grid":    {"colors", "size"},
+	"noise":   {"colors", "seed"},
+	"perlin":  {"colors", "seed", "scale", "octaves"},
+	"bars":    {},
+}
+
+// Colors of 100% color bars from left to right
+var colorBars = [][3]byte{{255, 255, 255}, {255, 255, 0}, {0, 255, 255}, {0, 255, 0}, {255, 0, 255}, {255, 0, 0}, {0, 0, 255}, {0, 0, 0}}
+
+// New returns the black 24 bit image with BITMAPINFOHEADER
+func New(width, height int) (*bmp, error) {
+	if width <= 0 || height <= 0 {
+		return nil, ErrIncorrectImageSize
+	} else if err := checkSize(width, height); err != nil {
+		return nil, err
+	}
+	b := &bmp{
+		fileHeader: &fileHeader{Signature: BMPsignature, Offset: 54},
+		dibHeader: &dibHeader{Size: 40, ColorPlane: 1, BitsPerPixel: 24,
+			HorizontalResolution: newResolution, VerticalResolution: newResolution},
+	}
+	b.setSize(uint32(width), uint32(height))
+	return b, nil
+}
+
+// Generate paints the whole image by the pattern
+// value format: <pattern>[:<options>], options are separated by comma:
+// colors=<rrggbb>-<rrggbb>-... are the stops of gradients spread evenly, black to white by default,
+// solid takes one color, checker and grid take two colors;
+// patterns: solid; linear with angle in degrees, 0 goes from left to right and 90 from top to bottom;
+// radial with center=<x>x<y> and radius, the farthest corner by default; conic with center and angle of start;
+// checker and grid with size of cell, 16 by default; noise is white noise, perlin is fractal Perlin noise,
+// both with seed, perlin with scale of cell in pixels, 32 by default, and octaves, 4 by default; bars are color bars
+func (b *bmp) Generate(flagValue string) error {
+	pattern, rest := splitCanvasValue(flagValue)
+	allowed, ok := patternOptions[pattern]
+	if !ok {
+		return ErrIncorrectPatternValue
+	}
+
+	options := map[string]string{}
+	if rest != "" {
+		if options, ok = utils.ParseOptions(rest); !ok {
+			return ErrIncorrectPatternValue
+		}
+	}
+	colors := [][3]byte{{0, 0, 0}, {255, 255, 255}}
+	if pattern == "solid" {
+		colors = colors[:1]
+	}
+	angle, radius, size, seed, scale, octaves := 0., 0., patternCellSize, 0, float64(perlinScale), perlinOctaves
+	for key, value := range options {
+		if utils.In(key, allowed) == -1 {
+			return ErrIncorrectPatternValue
+		}
+		switch key {
+		case "colors":
+			colors = nil
+			for _, hex := range utils.Split(value, "-") {
+				red, green, blue, ok := utils.ParseHexColor(hex)
+				if !ok {
+					return ErrIncorrectPatternValue
+				}
+				colors = append(colors, [3]byte{red, green, blue})
+			}
+			ok = (pattern == "solid") == (len(colors) == 1) && (pattern != "checker" && pattern != "grid" || len(colors) == 2)
+		case "angle":
+			angle, ok = utils.ParseFloat(value)
+		case "radius":
+			radius, ok = utils.ParseFloat(value)
+			ok = ok && radius > 0
+		case "size":
+			size, ok = utils.Atoi(value)
+			ok = ok && size > 0
+		case "seed":
+			seed, ok = utils.Atoi(value)
+		case "scale":
+			scale, ok = utils.ParseFloat(value)
+			ok = ok && scale > 0
+		case "octaves":
+			octaves, ok = utils.Atoi(value)
+			ok = ok && octaves >= 1 && octaves <= 8
+		}
+		if !ok {
+			return ErrIncorrectPatternValue
+		}
+	}
+	centerX, centerY, err := b.opticalCenter(options["center"], ErrIncorrectPatternValue)
+	if err != nil {
+		return err
+	}
+
+	width, height := int(b.dibHeader.Width), len(b.pixelArray)
+	if radius == 0 {
+		radius = math.Hypot(max(centerX, float64(width-1)-centerX), max(centerY, float64(height-1)-centerY))
+	}
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	// Half of image extent along the direction of linear gradient
+	extent := (math.Abs(float64(width-1)*cos) + math.Abs(float64(height-1)*sin)) / 2
+	random := rand.New(rand.NewSource(int64(seed)))
+	var noise *perlinNoise
+	if pattern == "perlin" {
+		noise = newPerlinNoise(random)
+	}
+
+	for y := 0; y < height; y++ {
+		row := b.row(y)
+		for x := 0; x < width; x++ {
+			var color [3]byte
+			switch pattern {
+			case "solid":
+				color = colors[0]
+			case "linear":
+				t := 0.
+				if extent > 0 {
+					t = ((float64(x)-float64(width-1)/2)*cos+(float64(y)-float64(height-1)/2)*sin)/extent/2 + 0.5
+				}
+				color = gradientColor(colors, t)
+			case "radial":
+				color = gradientColor(colors, math.Hypot(float64(x)-centerX, float64(y)-centerY)/radius)
+			case "conic":
+				degrees := math.Atan2(float64(y)-centerY, float64(x)-centerX)*180/math.Pi - angle
+				color = gradientColor(colors, math.Mod(math.Mod(degrees, 360)+360, 360)/360)
+			case "checker":
+				color = colors[(x/size+y/size)%2]
+			case "grid":
+				color = colors[0]
+				if x%size == 0 || y%size == 0 || x == width-1 || y == height-1 {
+					color = colors[1]
+				}
+			case "noise":
+				color = gradientColor(colors, random.Float64())
+			case "perlin":
+				color = gradientColor(colors, noise.fractal(float64(x)/scale, float64(y)/scale, octaves)/2+0.5)
+			case "bars":
+				color = colorBars[x*len(colorBars)/width]
+			}
+			row[x*3], row[x*3+1], row[x*3+2] = color[2], color[1], color[0]
+		}
+	}
+	return nil
+}
+
+// gradientColor returns the color of gradient with evenly spread stops at position t from 0 to 1
+func gradientColor(colors [][3]byte, t float64) [3]byte {
+	position := max(0, min(1, t)) * float64(len(colors)-1)
+	idx := min(int(position), len(colors)-2)
+	if idx < 0 {
+		return colors[0]
+	}
+	fraction := position - float64(idx)
+	var color [3]byte
+	for channel := range color {
+		from, to := float64(colors[idx][channel]), float64(colors[idx+1][channel])
+		color[channel] = clampByte(from + (to-from)*fraction)
+	}
+	return color
+}
+
+// Perlin noise of permutation table doubled to skip wrapping of indexes
+// see (https://en.wikipedia.org/wiki/Perlin_noise)
+type perlinNoise struct {
+	permutation [512]int
+}
+
+// newPerlinNoise returns the noise of permutation shuffled by the random source
+func newPerlinNoise(random *rand.Rand) *perlinNoise {
+	noise := &perlinNoise{}
+	for idx, value := range random.Perm(256) {
+		noise.permutation[idx], noise.permutation[idx+256] = value, value
+	}
+	return noise
+}
+
+// value returns the noise at the point in range about [-1, 1], it is 0 at integer points
+func (p *perlinNoise) value(x, y float64) float64 {
+	cellX, cellY := math.Floor(x), math.Floor(y)
+	xi, yi := int(cellX)&255, int(cellY)&255
+	x, y = x-cellX, y-cellY
+
+	// Fade curve 6t^5 - 15t^4 + 10t^3 smooths interpolation between corners
+	fade := func(t float64) float64 {
+		return t * t * t * (t*(t*6-15) + 10)
+	}
+	// gradient returns the dot product of one of 8 gradient directions chosen by hash and the offset
+	gradient := func(hash int, dx, dy float64) float64 {
+		directions := [8][2]float64{{1, 1}, {-1, 1}, {1, -1}, {-1, -1}, {1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+		direction := directions[hash&7]
+		return direction[0]*dx + direction[1]*dy
+	}
+	lerp := func(t, a, b float64) float64 {
+		return a + t*(b-a)
+	}
+
+	perm := p.permutation
+	u, v := fade(x), fade(y)
+	return lerp(v,
+		lerp(u, gradient(perm[perm[xi]+yi], x, y), gradient(perm[perm[xi+1]+yi], x-1, y)),
+		lerp(u, gradient(perm[perm[xi]+yi+1], x, y-1), gradient(perm[perm[xi+1]+yi+1], x-1, y-1)))
+}
+
+// fractal sums octaves of noise, every octave has double frequency and half amplitude, the sum is normalized
+func (p *perlinNoise) fractal(x, y float64, octaves int) float64 {
+	sum, amplitude, total := 0., 1., 0.
+	for octave := 0; octave < octaves; octave++ {
+		sum += p.value(x, y) * amplitude
+		total += amplitude
+		x, y, amplitude = x*2, y*2, amplitude/2
+	}
+	return sum / total
+}
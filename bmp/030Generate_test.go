@@ -0,0 +1,84 @@
+package bmp
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	testBmp, err := New(5, 3)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	fileName := filepath.Join(t.TempDir(), "new.bmp")
+	if err := testBmp.Save(fileName); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(fileName)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.dibHeader.Width != 5 || len(loaded.pixelArray) != 3 || loaded.GetPixelNumber() != 24 {
+		t.Errorf("New() saved image is %dx%d of %d bits, want 5x3 of 24 bits", loaded.dibHeader.Width, len(loaded.pixelArray), loaded.GetPixelNumber())
+	}
+
+	if _, err := New(0, 3); err != ErrIncorrectImageSize {
+		t.Errorf("New() error = %v, want %v", err, ErrIncorrectImageSize)
+	}
+	if _, err := New(100000, 100000); err != ErrImageTooLarge {
+		t.Errorf("New() error = %v, want %v", err, ErrImageTooLarge)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	type testData struct {
+		name   string
+		value  string
+		pixels map[[2]int][3]byte
+	}
+
+	tests := []testData{
+		{name: "Solid", value: "solid:colors=336699", pixels: map[[2]int][3]byte{{0, 0}: {0x99, 0x66, 0x33}, {8, 4}: {0x99, 0x66, 0x33}}},
+		{name: "Linear", value: "linear:colors=000000-ff0000-ffffff", pixels: map[[2]int][3]byte{{0, 0}: {0, 0, 0}, {4, 2}: {0, 0, 255}, {8, 4}: {255, 255, 255}}},
+		{name: "Linear vertical", value: "linear:angle=90", pixels: map[[2]int][3]byte{{8, 0}: {0, 0, 0}, {0, 4}: {255, 255, 255}}},
+		{name: "Radial", value: "radial:center=0x0,radius=4", pixels: map[[2]int][3]byte{{0, 0}: {0, 0, 0}, {2, 0}: {128, 128, 128}, {8, 4}: {255, 255, 255}}},
+		{name: "Conic", value: "conic:center=4x2", pixels: map[[2]int][3]byte{{8, 2}: {0, 0, 0}, {0, 2}: {128, 128, 128}}},
+		{name: "Checker", value: "checker:size=2", pixels: map[[2]int][3]byte{{1, 1}: {0, 0, 0}, {2, 1}: {255, 255, 255}, {2, 2}: {0, 0, 0}}},
+		{name: "Grid", value: "grid:size=4,colors=ffffff-000000", pixels: map[[2]int][3]byte{{4, 1}: {0, 0, 0}, {1, 1}: {255, 255, 255}, {8, 3}: {0, 0, 0}}},
+		{name: "Bars", value: "bars", pixels: map[[2]int][3]byte{{0, 0}: {255, 255, 255}, {2, 0}: {0, 255, 255}, {8, 4}: {0, 0, 0}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testBmp, _ := New(9, 5)
+			if err := testBmp.Generate(test.value); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			for point, want := range test.pixels {
+				if pixel := [3]byte(testBmp.row(point[1])[point[0]*3 : point[0]*3+3]); pixel != want {
+					t.Errorf("Generate() pixel %v = %v, want %v", point, pixel, want)
+				}
+			}
+		})
+	}
+
+	// Noise is the same for the same seed
+	first, _ := New(16, 16)
+	second, _ := New(16, 16)
+	for _, value := range []string{"noise:seed=3", "perlin:seed=3,octaves=2"} {
+		first.Generate(value)
+		second.Generate(value)
+		for idx := range first.pixelArray {
+			if string(first.pixelArray[idx]) != string(second.pixelArray[idx]) {
+				t.Errorf("Generate(%q) differs for the same seed", value)
+				break
+			}
+		}
+	}
+
+	for _, value := range []string{"stripes", "solid:colors=000000-ffffff", "checker:seed=1", "grid:colors=000000-ffffff-ff0000", "perlin:octaves=9"} {
+		if err := first.Generate(value); err != ErrIncorrectPatternValue {
+			t.Errorf("Generate(%q) error = %v, want %v", value, err, ErrIncorrectPatternValue)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package bmp
+
+// newTestBmp returns 24 bit image filled with the color in BGR order
+func newTestBmp(width, height int, color [3]byte) *bmp {
+	// Rows are padded to 4 bytes
+	rowSize := (width*3 + 3) / 4 * 4
+	testBmp := &bmp{
+		fileHeader: &fileHeader{Signature: BMPsignature, Offset: 54, FileSize: uint32(54 + rowSize*height)},
+		dibHeader: &dibHeader{Size: 40, Width: uint32(width), Height: uint32(height), ColorPlane: 1, BitsPerPixel: 24,
+			ImageSize: uint32(rowSize * height)},
+		pixelArray: make([][]byte, height),
+	}
+	for rowIdx := range testBmp.pixelArray {
+		testBmp.pixelArray[rowIdx] = make([]byte, rowSize)
+		for colIdx := 0; colIdx < width; colIdx++ {
+			copy(testBmp.pixelArray[rowIdx][colIdx*3:], color[:])
+		}
+	}
+	return testBmp
+}
+
+// litPixels returns the map of pixels which blue channel differs from the background
+func litPixels(b *bmp) map[[2]int]byte {
+	lit := make(map[[2]int]byte)
+	for y := 0; y < len(b.pixelArray); y++ {
+		for x := 0; x < int(b.dibHeader.Width); x++ {
+			if blue := b.row(y)[x*3]; blue != 0 {
+				lit[[2]int{x, y}] = blue
+			}
+		}
+	}
+	return lit
+}
@@ -12,6 +12,11 @@ var (
 	Arguments  []Argument
 	SourceFile string
 	OutputFile string
+	// Prints details of processing
+	Verbose bool
+	// Inputs and output directory of thumbnail command
+	SourceFiles []string
+	OutputDir   string
 )
 
 // Struct of apply command's arguments
@@ -22,13 +27,37 @@ type Argument struct {
 
 // Private variables
 var (
-	commands     = []string{"header", "apply"}
+	commands     = []string{"header", "apply", "thumbnail", "new"}
 	helps        = []string{"-h", "--help", "help"}
-	mirrorValues = []string{"h", "hor", "horizontal", "horizontally", "v", "ver", "vertical", "vertically"}
-	filterValues = []string{"red", "green", "blue", "grayscale", "negative", "pixelate", "blur", "sepia"}
+	mirrorValues = []string{"h", "hor", "horizontal", "horizontally", "v", "ver", "vertical", "vertically", "transpose", "diagonal", "transverse", "anti-diagonal"}
+	filterValues = []string{"red", "green", "blue", "grayscale", "negative", "pixelate", "blur", "sepia", "threshold", "dither", "posterize", "solarize", "median", "mode", "bilateral", "nlm"}
 	rotateValues = []string{"right", "90", "180", "270", "left", "-90", "-180", "-270"}
+	depthValues  = []string{"1", "4", "8", "16", "24"}
+	// Options of apply written without value
+	switchValues = []string{"trim", "deskew", "verbose"}
+	// Parameters of filters
+	grayscaleValues = []string{"rec601", "rec709", "average", "lightness", "luminosity", "red", "green", "blue"}
+	thresholdValues = []string{"otsu", "mean", "gaussian"}
+	ditherValues    = []string{"none", "floyd-steinberg", "atkinson", "jarvis-judice-ninke", "sierra", "bayer"}
+	paletteValues   = []string{"mono", "gray4", "gray16", "gray256", "win16", "web216", "rgb555"}
+	quantizeValues  = []string{"median-cut", "octree", "kmeans"}
+	morphValues     = []string{"erode", "dilate", "open", "close", "gradient", "tophat", "blackhat"}
+	elementValues   = []string{"square", "cross", "disk"}
+	resampleValues  = []string{"nearest", "bilinear", "bicubic", "lanczos3", "area"}
+	resizeModes     = []string{"stretch", "fit", "letterbox", "fill", "seam"}
+	samplingValues  = []string{"nearest", "bilinear", "bicubic"}
+	regionValues    = []string{"rect", "ellipse", "mask", "wand"}
+	drawValues      = []string{"line", "arrow", "rect", "circle", "ellipse", "polygon"}
+	patternValues   = []string{"solid", "linear", "radial", "conic", "checker", "grid", "noise", "perlin", "bars"}
+	textOptions     = []string{"x", "y", "anchor", "color", "scale", "background", "font"}
+	blendValues     = []string{"normal", "multiply", "screen", "overlay", "soft-light", "hard-light", "difference", "darken", "lighten", "add", "subtract"}
+	anchorValues    = []string{"top-left", "top", "top-right", "left", "center", "right", "bottom-left", "bottom", "bottom-right"}
+	matrixValues    = []string{"identity", "red", "green", "blue", "sepia", "negative", "grayscale", "polaroid", "kodachrome", "vintage"}
 )
 
+// Limit of pixels of created or resized image, 8192x8192, the same as of bmp package
+const maxPixels = 1 << 26
+
 // Errors
 var (
 	HelpCommand                   = fmt.Errorf("Help command called")
@@ -77,7 +106,10 @@ func Parse(args []string) error {
 		for _, arg := range args[:len(args)-2] {
 			flagName, flagValue, err := getFlagNameAndValue("--", arg)
 			if err != nil {
-				return err
+				if !utils.HasPrefix("--", arg) || utils.In(arg[2:], switchValues) == -1 {
+					return err
+				}
+				flagName, flagValue = arg[2:], ""
 			}
 
 			// Argument handling
@@ -89,9 +121,21 @@ func Parse(args []string) error {
 					flagValue = "h"
 				} else if utils.In(flagValue, []string{"v", "ver", "vertical", "vertically"}) != -1 {
 					flagValue = "v"
+				} else if flagValue == "diagonal" {
+					flagValue = "transpose"
+				} else if flagValue == "anti-diagonal" {
+					flagValue = "transverse"
 				}
 			case "filter":
-				if utils.In(flagValue, filterValues) == -1 {
+				if err := validateFilter(flagValue); err != nil {
+					return err
+				}
+			case "depth":
+				// Format: <bits>[:<dithering method>]
+				params := utils.Split(flagValue, ":")
+				if utils.In(params[0], depthValues) == -1 || len(params) > 2 {
+					return ErrIncorrectArgumentValue
+				} else if len(params) == 2 && utils.In(params[1], ditherValues) == -1 {
 					return ErrIncorrectArgumentValue
 				}
 			case "rotate":
@@ -103,6 +147,102 @@ func Parse(args []string) error {
 					flagValue = "-90"
 				}
 
+			case "quantize":
+				if err := validateQuantize(flagValue); err != nil {
+					return err
+				}
+			case "morph":
+				if err := validateMorph(flagValue); err != nil {
+					return err
+				}
+			case "color-matrix":
+				// Either preset name or 20 numbers of matrix 4x5
+				if utils.In(flagValue, matrixValues) == -1 {
+					values := utils.Split(flagValue, ",")
+					if len(values) != 20 {
+						return ErrIncorrectArgumentValue
+					}
+					for _, value := range values {
+						if _, ok := utils.ParseFloat(value); !ok {
+							return ErrNotNumericArgumentValue
+						}
+					}
+				}
+			case "channels":
+				// Sources of red, green and blue channels
+				if len(flagValue) != 3 {
+					return ErrIncorrectArgumentValue
+				}
+				for _, char := range flagValue {
+					if utils.In(string(char), []string{"r", "g", "b", "0", "1"}) == -1 {
+						return ErrIncorrectArgumentValue
+					}
+				}
+			case "lut":
+				// Format: <file>[:<trilinear|tetrahedral>]
+				if flagValue == "" || flagValue[0] == ':' {
+					return ErrIncorrectArgumentValue
+				}
+			case "resize", "scale":
+				if err := validateResize(flagName, flagValue); err != nil {
+					return err
+				}
+			case "pad", "border", "canvas":
+				if err := validateCanvas(flagName, flagValue); err != nil {
+					return err
+				}
+			case "verbose":
+				if flagValue != "" {
+					return ErrIncorrectArgumentValue
+				}
+				Verbose = true
+				continue
+			case "trim":
+				// Optional tolerance of border color
+				if flagValue != "" {
+					if !utils.IsNumeric(flagValue) {
+						return ErrNotNumericArgumentValue
+					} else if tolerance, _ := utils.Atoi(flagValue); tolerance > 255 {
+						return ErrIncorrectArgumentValue
+					}
+				}
+			case "affine", "perspective":
+				if err := validateTransform(flagName, flagValue); err != nil {
+					return err
+				}
+			case "deskew":
+				// Optional maximum angle in degrees
+				if flagValue != "" {
+					if !utils.IsNumeric(flagValue) {
+						return ErrNotNumericArgumentValue
+					} else if angle, _ := utils.Atoi(flagValue); angle < 1 || angle > 45 {
+						return ErrIncorrectArgumentValue
+					}
+				}
+			case "lens", "vignette":
+				if err := validateLens(flagName, flagValue); err != nil {
+					return err
+				}
+			case "overlay":
+				if err := validateOverlay(flagValue); err != nil {
+					return err
+				}
+			case "region":
+				if err := validateRegion(flagValue); err != nil {
+					return err
+				}
+			case "flood-fill":
+				if err := validateFloodFill(flagValue); err != nil {
+					return err
+				}
+			case "draw":
+				if err := validateDraw(flagValue); err != nil {
+					return err
+				}
+			case "text":
+				if err := validateText(flagValue); err != nil {
+					return err
+				}
 			case "crop":
 				// Size validation
 				sizes := utils.Split(flagValue, "-")
@@ -127,12 +267,854 @@ func Parse(args []string) error {
 		SourceFile = args[len(args)-2]
 		OutputFile = args[len(args)-1]
 		return nil
+	case Command == "thumbnail":
+		if len(args) == 0 || utils.In(args[0], helps) != -1 {
+			return HelpCommand
+		}
+
+		// Options go first, then source files and output directory
+		for len(args) > 0 && utils.HasPrefix("--", args[0]) {
+			flagName, flagValue, err := getFlagNameAndValue("--", args[0])
+			if err != nil {
+				return err
+			}
+
+			switch flagName {
+			case "size", "jobs":
+				if !utils.IsNumeric(flagValue) {
+					return ErrNotNumericArgumentValue
+				} else if value, _ := utils.Atoi(flagValue); value < 1 {
+					return ErrIncorrectArgumentValue
+				}
+			case "sharpen":
+				if amount, ok := utils.ParseFloat(flagValue); !ok {
+					return ErrNotNumericArgumentValue
+				} else if amount < 0 {
+					return ErrIncorrectArgumentValue
+				}
+			case "name":
+				// Names of thumbnails must differ, so the template includes the source name or index
+				if utils.Replace(flagValue, "{name}", "") == flagValue && utils.Replace(flagValue, "{index}", "") == flagValue {
+					return ErrIncorrectArgumentValue
+				}
+			default:
+				return ErrIncorrectOptionName
+			}
+
+			Arguments = append(Arguments, Argument{
+				Name:  flagName,
+				Value: flagValue,
+			})
+			args = args[1:]
+		}
+		if len(args) < 2 {
+			return ErrIncorrectNumberOfArguments
+		}
+
+		SourceFiles = args[:len(args)-1]
+		OutputDir = args[len(args)-1]
+		return nil
+	case Command == "new":
+		if len(args) == 0 || utils.In(args[0], helps) != -1 {
+			return HelpCommand
+		}
+
+		// Options go first, then output file
+		for len(args) > 0 && utils.HasPrefix("--", args[0]) {
+			flagName, flagValue, err := getFlagNameAndValue("--", args[0])
+			if err != nil {
+				return err
+			}
+
+			switch flagName {
+			case "size":
+				sizes := utils.Split(flagValue, "x")
+				if len(sizes) != 2 {
+					return ErrIncorrectArgumentFormat
+				}
+				for _, size := range sizes {
+					if !utils.IsNumeric(size) {
+						return ErrNotNumericArgumentValue
+					} else if value, ok := utils.Atoi(size); !ok || value < 1 {
+						return ErrIncorrectArgumentValue
+					}
+				}
+				width, _ := utils.Atoi(sizes[0])
+				height, _ := utils.Atoi(sizes[1])
+				if tooLarge(width, height) {
+					return ErrIncorrectArgumentValue
+				}
+			case "depth":
+				params := utils.Split(flagValue, ":")
+				if utils.In(params[0], depthValues) == -1 || len(params) > 2 {
+					return ErrIncorrectArgumentValue
+				} else if len(params) == 2 && utils.In(params[1], ditherValues) == -1 {
+					return ErrIncorrectArgumentValue
+				}
+			case "pattern":
+				if err := validatePattern(flagValue); err != nil {
+					return err
+				}
+			default:
+				return ErrIncorrectOptionName
+			}
+
+			Arguments = append(Arguments, Argument{
+				Name:  flagName,
+				Value: flagValue,
+			})
+			args = args[1:]
+		}
+		if len(args) != 1 {
+			return ErrIncorrectNumberOfArguments
+		}
+
+		OutputFile = args[0]
+		return nil
 	default:
 		Command = ""
 		return ErrIncorrectCommandName
 	}
 }
 
+// Validates the filter value with format: <filter_name>:<parameter>
+func validateFilter(flagValue string) error {
+	params := utils.Split(flagValue, ":")
+	if utils.In(params[0], filterValues) == -1 {
+		return ErrIncorrectArgumentValue
+	}
+
+	switch params[0] {
+	case "grayscale":
+		if len(params) > 2 || len(params) == 2 && utils.In(params[1], grayscaleValues) == -1 {
+			return ErrIncorrectArgumentValue
+		}
+	case "threshold":
+		// Either fixed threshold or method with optional numeric parameters
+		if len(params) < 2 {
+			return ErrIncorrectArgumentValue
+		} else if utils.In(params[1], thresholdValues) == -1 {
+			if value, ok := utils.Atoi(params[1]); !ok || value < 0 || value > 255 || len(params) > 2 {
+				return ErrIncorrectArgumentValue
+			}
+		} else if params[1] == "otsu" && len(params) > 2 || len(params) > 4 {
+			return ErrIncorrectArgumentValue
+		}
+		for idx, param := range params[2:] {
+			value, ok := utils.Atoi(param)
+			if !ok {
+				return ErrNotNumericArgumentValue
+			}
+			// Radius of adaptive methods goes first
+			if idx == 0 && value < 1 {
+				return ErrIncorrectArgumentValue
+			}
+		}
+	case "posterize", "solarize":
+		// Optional numeric parameter: number of levels or threshold
+		if len(params) > 2 {
+			return ErrIncorrectArgumentValue
+		} else if len(params) == 2 {
+			if !utils.IsNumeric(params[1]) || params[1] == "" {
+				return ErrNotNumericArgumentValue
+			}
+			value, _ := utils.Atoi(params[1])
+			if params[0] == "posterize" && (value < 2 || value > 256) || params[0] == "solarize" && value > 255 {
+				return ErrIncorrectArgumentValue
+			}
+		}
+	case "median", "mode", "bilateral", "nlm":
+		// Radius and strength, strength is applicable only to bilateral and nlm
+		if len(params) > 3 || len(params) == 3 && (params[0] == "median" || params[0] == "mode") {
+			return ErrIncorrectArgumentValue
+		}
+		for idx, param := range params[1:] {
+			if !utils.IsNumeric(param) || param == "" {
+				return ErrNotNumericArgumentValue
+			} else if value, _ := utils.Atoi(param); value < 1 {
+				return ErrIncorrectArgumentValue
+			} else if idx == 0 && (params[0] == "bilateral" && value > 25 || params[0] == "nlm" && value > 15) {
+				// Work of bilateral and nlm grows with the square of radius
+				return ErrIncorrectArgumentValue
+			}
+		}
+	case "dither":
+		if len(params) < 2 || len(params) > 3 || utils.In(params[1], ditherValues) == -1 {
+			return ErrIncorrectArgumentValue
+		} else if len(params) == 3 && utils.In(params[2], paletteValues) == -1 {
+			return ErrIncorrectArgumentValue
+		}
+	default:
+		if len(params) > 1 {
+			return ErrIncorrectArgumentValue
+		}
+	}
+
+	return nil
+}
+
+// Validates the quantize value with format: colors:<2-256>,method=<method>,dither=<method> or palette:<file>,dither=<method>
+func validateQuantize(flagValue string) error {
+	options, ok := utils.ParseOptions(flagValue)
+	if !ok {
+		return ErrIncorrectArgumentFormat
+	}
+
+	for key, value := range options {
+		switch key {
+		case "colors":
+			colors, ok := utils.Atoi(value)
+			if !ok {
+				return ErrNotNumericArgumentValue
+			} else if colors < 2 || colors > 256 {
+				return ErrIncorrectArgumentValue
+			}
+		case "method":
+			if utils.In(value, quantizeValues) == -1 {
+				return ErrIncorrectArgumentValue
+			}
+		case "dither":
+			if utils.In(value, ditherValues) == -1 {
+				return ErrIncorrectArgumentValue
+			}
+		case "palette":
+			// Palette is either loaded from file or generated
+			if _, ok := options["colors"]; ok {
+				return ErrIncorrectArgumentValue
+			} else if _, ok := options["method"]; ok {
+				return ErrIncorrectArgumentValue
+			}
+		default:
+			return ErrIncorrectOptionName
+		}
+	}
+
+	return nil
+}
+
+// Validates the morph value with format: <operation>[:element=<element>,radius=<n>,iterations=<n>,mode=<gray|binary>]
+func validateMorph(flagValue string) error {
+	operation, rest := flagValue, ""
+	for idx := range flagValue {
+		if flagValue[idx] == ':' {
+			operation, rest = flagValue[:idx], flagValue[idx+1:]
+			break
+		}
+	}
+	if utils.In(operation, morphValues) == -1 {
+		return ErrIncorrectArgumentValue
+	} else if rest == "" {
+		return nil
+	}
+
+	options, ok := utils.ParseOptions(rest)
+	if !ok {
+		return ErrIncorrectArgumentFormat
+	}
+	for key, value := range options {
+		switch key {
+		case "element":
+			if utils.In(value, elementValues) != -1 {
+				continue
+			}
+			// Custom element: rows of 0 and 1 separated by dash
+			for _, row := range utils.Split(value, "-") {
+				for _, char := range row {
+					if char != '0' && char != '1' {
+						return ErrIncorrectArgumentValue
+					}
+				}
+			}
+		case "radius", "iterations":
+			if !utils.IsNumeric(value) {
+				return ErrNotNumericArgumentValue
+			} else if number, _ := utils.Atoi(value); number < 1 || key == "radius" && number > 50 {
+				return ErrIncorrectArgumentValue
+			}
+		case "mode":
+			if value != "gray" && value != "binary" {
+				return ErrIncorrectArgumentValue
+			}
+		default:
+			return ErrIncorrectOptionName
+		}
+	}
+
+	return nil
+}
+
+// Validates the resize value with format: <width>x<height>[:filter=<filter>,mode=<mode>,color=<rrggbb>]
+// and the scale value with format: <percent>%[:filter=<filter>]
+func validateResize(flagName, flagValue string) error {
+	size, rest := flagValue, ""
+	for idx := range flagValue {
+		if flagValue[idx] == ':' {
+			size, rest = flagValue[:idx], flagValue[idx+1:]
+			break
+		}
+	}
+
+	if flagName == "resize" {
+		dimensions := utils.Split(size, "x")
+		if len(dimensions) != 2 || dimensions[0] == "" || dimensions[1] == "" {
+			return ErrIncorrectArgumentValue
+		}
+		for _, dimension := range dimensions {
+			if !utils.IsNumeric(dimension) {
+				return ErrNotNumericArgumentValue
+			}
+		}
+		// Values not fitting into int are too large as well
+		width, okWidth := utils.Atoi(dimensions[0])
+		height, okHeight := utils.Atoi(dimensions[1])
+		if !okWidth || !okHeight || width == 0 && height == 0 || tooLarge(width, height) {
+			return ErrIncorrectArgumentValue
+		}
+	} else {
+		if len(size) > 0 && size[len(size)-1] == '%' {
+			size = size[:len(size)-1]
+		}
+		if percent, ok := utils.ParseFloat(size); !ok {
+			return ErrNotNumericArgumentValue
+		} else if percent <= 0 {
+			return ErrIncorrectArgumentValue
+		}
+	}
+
+	if rest == "" {
+		return nil
+	}
+	options, ok := utils.ParseOptions(rest)
+	if !ok {
+		return ErrIncorrectArgumentFormat
+	}
+	for key, value := range options {
+		switch {
+		case key == "filter":
+			if utils.In(value, resampleValues) == -1 {
+				return ErrIncorrectArgumentValue
+			}
+		case key == "mode" && flagName == "resize":
+			if utils.In(value, resizeModes) == -1 {
+				return ErrIncorrectArgumentValue
+			}
+		case key == "color" && flagName == "resize":
+			if _, _, _, ok := utils.ParseHexColor(value); !ok {
+				return ErrIncorrectArgumentValue
+			}
+		case (key == "protect" || key == "remove") && flagName == "resize":
+			// Masks are applicable only to seam carving
+			if options["mode"] != "seam" {
+				return ErrIncorrectArgumentValue
+			}
+		default:
+			return ErrIncorrectOptionName
+		}
+	}
+
+	return nil
+}
+
+// tooLarge reports whether the image of width and height exceeds the limit of pixels,
+// sides are checked separately so that their product doesn't overflow
+func tooLarge(width, height int) bool {
+	return width > maxPixels || height > maxPixels || width*height > maxPixels
+}
+
+// Validates the pad value with format: <top>,<right>,<bottom>,<left>[:<fill>] or <margin>[:<fill>],
+// the border value with format: <width>[:<fill>] and the canvas value with format: <width>x<height>[:anchor=<anchor>,color=<fill>]
+func validateCanvas(flagName, flagValue string) error {
+	size, rest := flagValue, ""
+	for idx := range flagValue {
+		if flagValue[idx] == ':' {
+			size, rest = flagValue[:idx], flagValue[idx+1:]
+			break
+		}
+	}
+
+	// Fill is either color rrggbb, edge or mirror
+	validateFill := func(fill string) error {
+		if _, _, _, ok := utils.ParseHexColor(fill); !ok && fill != "edge" && fill != "mirror" {
+			return ErrIncorrectArgumentValue
+		}
+		return nil
+	}
+
+	switch flagName {
+	case "pad", "border":
+		// Border has the same width on every side
+		values := utils.Split(size, ",")
+		if len(values) != 1 && len(values) != 4 || flagName == "border" && len(values) != 1 {
+			return ErrIncorrectArgumentValue
+		}
+		for _, value := range values {
+			if !utils.IsNumeric(value) || value == "" {
+				return ErrNotNumericArgumentValue
+			} else if margin, ok := utils.Atoi(value); !ok || margin > maxPixels {
+				return ErrIncorrectArgumentValue
+			}
+		}
+		if width, _ := utils.Atoi(size); flagName == "border" && width < 1 {
+			return ErrIncorrectArgumentValue
+		}
+		if rest != "" {
+			return validateFill(rest)
+		}
+	case "canvas":
+		dimensions := utils.Split(size, "x")
+		if len(dimensions) != 2 {
+			return ErrIncorrectArgumentValue
+		}
+		for _, dimension := range dimensions {
+			if !utils.IsNumeric(dimension) || dimension == "" {
+				return ErrNotNumericArgumentValue
+			} else if value, ok := utils.Atoi(dimension); !ok || value < 1 {
+				return ErrIncorrectArgumentValue
+			}
+		}
+		width, _ := utils.Atoi(dimensions[0])
+		height, _ := utils.Atoi(dimensions[1])
+		if tooLarge(width, height) {
+			return ErrIncorrectArgumentValue
+		}
+		if rest == "" {
+			return nil
+		}
+
+		options, ok := utils.ParseOptions(rest)
+		if !ok {
+			return ErrIncorrectArgumentFormat
+		}
+		for key, value := range options {
+			switch key {
+			case "anchor":
+				if utils.In(value, anchorValues) == -1 {
+					return ErrIncorrectArgumentValue
+				}
+			case "color":
+				if err := validateFill(value); err != nil {
+					return err
+				}
+			default:
+				return ErrIncorrectOptionName
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validates the affine value with format: <a>,<b>,<c>,<d>,<e>,<f>[:<options>] or <options>
+// and the perspective value with format: <x1>,<y1>,<x2>,<y2>,<x3>,<y3>,<x4>,<y4>[:<options>]
+func validateTransform(flagName, flagValue string) error {
+	numbers, rest := "", flagValue
+	if flagName == "perspective" || flagValue != "" && (utils.IsNumeric(flagValue[:1]) || flagValue[0] == '-' || flagValue[0] == '.') {
+		numbers, rest = flagValue, ""
+		for idx := range flagValue {
+			if flagValue[idx] == ':' {
+				numbers, rest = flagValue[:idx], flagValue[idx+1:]
+				break
+			}
+		}
+	}
+
+	if numbers != "" || flagName == "perspective" {
+		values := utils.Split(numbers, ",")
+		if flagName == "affine" && len(values) != 6 || flagName == "perspective" && len(values) != 8 {
+			return ErrIncorrectArgumentValue
+		}
+		for _, value := range values {
+			if _, ok := utils.ParseFloat(value); !ok {
+				return ErrNotNumericArgumentValue
+			}
+		}
+	}
+	if rest == "" {
+		return nil
+	}
+
+	options, ok := utils.ParseOptions(rest)
+	if !ok {
+		return ErrIncorrectArgumentFormat
+	}
+	for key, value := range options {
+		switch {
+		case key == "filter":
+			if utils.In(value, samplingValues) == -1 {
+				return ErrIncorrectArgumentValue
+			}
+		case key == "color":
+			if _, _, _, ok := utils.ParseHexColor(value); !ok && value != "edge" && value != "mirror" {
+				return ErrIncorrectArgumentValue
+			}
+		case key == "size" && flagName == "perspective":
+			dimensions := utils.Split(value, "x")
+			if len(dimensions) != 2 || !utils.IsNumeric(dimensions[0]) || !utils.IsNumeric(dimensions[1]) {
+				return ErrIncorrectArgumentValue
+			}
+		case utils.In(key, []string{"scale", "shear", "rotate", "translate"}) != -1 && flagName == "affine" && numbers == "":
+			for _, number := range utils.Split(value, "x") {
+				if _, ok := utils.ParseFloat(number); !ok {
+					return ErrNotNumericArgumentValue
+				}
+			}
+		default:
+			return ErrIncorrectOptionName
+		}
+	}
+
+	return nil
+}
+
+// Validates the lens value with format: k1=<k1>,k2=<k2>,center=<x>x<y>,filter=<filter>,color=<fill>
+// and the vignette value with format: strength=<-1..1>,radius=<radius>,center=<x>x<y>
+func validateLens(flagName, flagValue string) error {
+	options, ok := utils.ParseOptions(flagValue)
+	if !ok {
+		return ErrIncorrectArgumentFormat
+	}
+	if flagName == "lens" && options["k1"] == "" && options["k2"] == "" || flagName == "vignette" && options["strength"] == "" {
+		return ErrIncorrectArgumentValue
+	}
+
+	for key, value := range options {
+		switch {
+		case key == "center":
+			coordinates := utils.Split(value, "x")
+			if len(coordinates) != 2 {
+				return ErrIncorrectArgumentValue
+			}
+			for _, coordinate := range coordinates {
+				if _, ok := utils.ParseFloat(coordinate); !ok {
+					return ErrNotNumericArgumentValue
+				}
+			}
+		case (key == "k1" || key == "k2") && flagName == "lens":
+			if _, ok := utils.ParseFloat(value); !ok {
+				return ErrNotNumericArgumentValue
+			}
+		case key == "filter" && flagName == "lens":
+			if utils.In(value, samplingValues) == -1 {
+				return ErrIncorrectArgumentValue
+			}
+		case key == "color" && flagName == "lens":
+			if _, _, _, ok := utils.ParseHexColor(value); !ok && value != "edge" && value != "mirror" {
+				return ErrIncorrectArgumentValue
+			}
+		case key == "strength" && flagName == "vignette":
+			if strength, ok := utils.ParseFloat(value); !ok {
+				return ErrNotNumericArgumentValue
+			} else if strength < -1 || strength > 1 {
+				return ErrIncorrectArgumentValue
+			}
+		case key == "radius" && flagName == "vignette":
+			if radius, ok := utils.ParseFloat(value); !ok {
+				return ErrNotNumericArgumentValue
+			} else if radius <= 0 {
+				return ErrIncorrectArgumentValue
+			}
+		default:
+			return ErrIncorrectOptionName
+		}
+	}
+
+	return nil
+}
+
+// Validates the overlay value with format: <file>[:x=<x>,y=<y>,anchor=<anchor>,opacity=<0..1>,tile=<true|false>,mode=<blend mode>]
+func validateOverlay(flagValue string) error {
+	fileName, rest := flagValue, ""
+	for idx := range flagValue {
+		if flagValue[idx] == ':' {
+			fileName, rest = flagValue[:idx], flagValue[idx+1:]
+			break
+		}
+	}
+	if fileName == "" {
+		return ErrIncorrectArgumentValue
+	} else if rest == "" {
+		return nil
+	}
+
+	options, ok := utils.ParseOptions(rest)
+	if !ok {
+		return ErrIncorrectArgumentFormat
+	}
+	for key, value := range options {
+		switch key {
+		case "x", "y":
+			if _, ok := utils.Atoi(value); !ok {
+				return ErrNotNumericArgumentValue
+			}
+		case "anchor":
+			if utils.In(value, anchorValues) == -1 {
+				return ErrIncorrectArgumentValue
+			}
+		case "opacity":
+			if opacity, ok := utils.ParseFloat(value); !ok {
+				return ErrNotNumericArgumentValue
+			} else if opacity < 0 || opacity > 1 {
+				return ErrIncorrectArgumentValue
+			}
+		case "tile":
+			if value != "true" && value != "false" {
+				return ErrIncorrectArgumentValue
+			}
+		case "mode":
+			if utils.In(value, blendValues) == -1 {
+				return ErrIncorrectArgumentValue
+			}
+		default:
+			return ErrIncorrectOptionName
+		}
+	}
+
+	return nil
+}
+
+// Validates the text value with format: <text>[:<options>], options go after the last colon,
+// the part after colon is a part of text when it isn't the list of text options, e.g. time 12:30
+func validateText(flagValue string) error {
+	text, options := flagValue, map[string]string(nil)
+	for idx := len(flagValue) - 1; idx >= 0; idx-- {
+		if flagValue[idx] != ':' {
+			continue
+		}
+		parsed, ok := utils.ParseOptions(flagValue[idx+1:])
+		if !ok {
+			break
+		}
+		text, options = flagValue[:idx], parsed
+		for key := range options {
+			if utils.In(key, textOptions) == -1 {
+				text, options = flagValue, nil
+				break
+			}
+		}
+		break
+	}
+	if text == "" {
+		return ErrIncorrectArgumentValue
+	}
+
+	for key, value := range options {
+		switch key {
+		case "x", "y":
+			if _, ok := utils.Atoi(value); !ok {
+				return ErrNotNumericArgumentValue
+			}
+		case "anchor":
+			if utils.In(value, anchorValues) == -1 {
+				return ErrIncorrectArgumentValue
+			}
+		case "color", "background":
+			if _, _, _, ok := utils.ParseHexColor(value); !ok {
+				return ErrIncorrectArgumentValue
+			}
+		case "scale":
+			if !utils.IsNumeric(value) {
+				return ErrNotNumericArgumentValue
+			} else if scale, _ := utils.Atoi(value); scale < 1 {
+				return ErrIncorrectArgumentValue
+			}
+		}
+	}
+	return nil
+}
+
+// Validates the draw value with format: <shape>:<coordinates>[:color=<rrggbb>,width=<n>,fill=<true|false>,aa=<true|false>]
+func validateDraw(flagValue string) error {
+	parts := utils.Split(flagValue, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return ErrIncorrectArgumentFormat
+	} else if utils.In(parts[0], drawValues) == -1 {
+		return ErrIncorrectArgumentValue
+	}
+
+	coordinates := utils.Split(parts[1], ",")
+	for _, coordinate := range coordinates {
+		if _, ok := utils.Atoi(coordinate); !ok {
+			return ErrNotNumericArgumentValue
+		}
+	}
+	switch count := len(coordinates); parts[0] {
+	case "line", "arrow", "rect", "ellipse":
+		if count != 4 {
+			return ErrIncorrectArgumentValue
+		}
+	case "circle":
+		if count != 3 {
+			return ErrIncorrectArgumentValue
+		}
+	case "polygon":
+		if count < 6 || count%2 != 0 {
+			return ErrIncorrectArgumentValue
+		}
+	}
+	// Sizes of rectangle and radii can't be negative
+	if parts[0] == "rect" || parts[0] == "circle" || parts[0] == "ellipse" {
+		for _, size := range coordinates[2:] {
+			if value, _ := utils.Atoi(size); value < 0 || (parts[0] == "rect" && value == 0) {
+				return ErrIncorrectArgumentValue
+			}
+		}
+	}
+	if len(parts) == 2 {
+		return nil
+	}
+
+	options, ok := utils.ParseOptions(parts[2])
+	if !ok {
+		return ErrIncorrectArgumentFormat
+	}
+	for key, value := range options {
+		switch key {
+		case "color":
+			if _, _, _, ok := utils.ParseHexColor(value); !ok {
+				return ErrIncorrectArgumentValue
+			}
+		case "width":
+			if !utils.IsNumeric(value) {
+				return ErrNotNumericArgumentValue
+			} else if width, _ := utils.Atoi(value); width < 1 {
+				return ErrIncorrectArgumentValue
+			}
+		case "fill", "aa":
+			if value != "true" && value != "false" {
+				return ErrIncorrectArgumentValue
+			}
+		default:
+			return ErrIncorrectOptionName
+		}
+	}
+	return nil
+}
+
+// Validates the flood fill value with format: <x>,<y>[:color=<rrggbb>,tolerance=<0..255>,connectivity=<4|8>]
+func validateFloodFill(flagValue string) error {
+	seed, rest := flagValue, ""
+	for idx := range flagValue {
+		if flagValue[idx] == ':' {
+			seed, rest = flagValue[:idx], flagValue[idx+1:]
+			break
+		}
+	}
+	coordinates := utils.Split(seed, ",")
+	if len(coordinates) != 2 {
+		return ErrIncorrectArgumentFormat
+	}
+	for _, coordinate := range coordinates {
+		if !utils.IsNumeric(coordinate) {
+			return ErrNotNumericArgumentValue
+		}
+	}
+	if rest == "" {
+		return nil
+	}
+
+	options, ok := utils.ParseOptions(rest)
+	if !ok {
+		return ErrIncorrectArgumentFormat
+	}
+	for key, value := range options {
+		switch key {
+		case "color":
+			if _, _, _, ok := utils.ParseHexColor(value); !ok {
+				return ErrIncorrectArgumentValue
+			}
+		case "tolerance":
+			if !utils.IsNumeric(value) {
+				return ErrNotNumericArgumentValue
+			} else if tolerance, _ := utils.Atoi(value); tolerance > 255 {
+				return ErrIncorrectArgumentValue
+			}
+		case "connectivity":
+			if value != "4" && value != "8" {
+				return ErrIncorrectArgumentValue
+			}
+		default:
+			return ErrIncorrectOptionName
+		}
+	}
+	return nil
+}
+
+// Validates the region value with format: <shape>:<parameters>[:feather=<pixels>,invert=<true|false>] or none
+func validateRegion(flagValue string) error {
+	if flagValue == "none" {
+		return nil
+	}
+	parts := utils.Split(flagValue, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return ErrIncorrectArgumentFormat
+	} else if utils.In(parts[0], regionValues) == -1 || parts[1] == "" {
+		return ErrIncorrectArgumentValue
+	}
+
+	// Mask is the file, other shapes have coordinates
+	if parts[0] != "mask" {
+		coordinates := utils.Split(parts[1], ",")
+		if (parts[0] == "wand") != (len(coordinates) == 2) || (parts[0] != "wand" && len(coordinates) != 4) {
+			return ErrIncorrectArgumentValue
+		}
+		for idx, coordinate := range coordinates {
+			if value, ok := utils.Atoi(coordinate); !ok {
+				return ErrNotNumericArgumentValue
+			} else if idx >= 2 && value <= 0 {
+				return ErrIncorrectArgumentValue
+			}
+		}
+	}
+	if len(parts) == 2 {
+		return nil
+	}
+
+	options, ok := utils.ParseOptions(parts[2])
+	if !ok {
+		return ErrIncorrectArgumentFormat
+	}
+	for key, value := range options {
+		switch key {
+		case "feather":
+			if !utils.IsNumeric(value) {
+				return ErrNotNumericArgumentValue
+			}
+		case "invert":
+			if value != "true" && value != "false" {
+				return ErrIncorrectArgumentValue
+			}
+		case "tolerance", "connectivity":
+			if parts[0] != "wand" {
+				return ErrIncorrectOptionName
+			} else if !utils.IsNumeric(value) {
+				return ErrNotNumericArgumentValue
+			} else if number, _ := utils.Atoi(value); (key == "tolerance" && number > 255) || (key == "connectivity" && number != 4 && number != 8) {
+				return ErrIncorrectArgumentValue
+			}
+		default:
+			return ErrIncorrectOptionName
+		}
+	}
+	return nil
+}
+
+// Validates the pattern value with format: <pattern>[:<options>], options of pattern are checked by its generation
+func validatePattern(flagValue string) error {
+	pattern, rest := flagValue, ""
+	for idx := range flagValue {
+		if flagValue[idx] == ':' {
+			pattern, rest = flagValue[:idx], flagValue[idx+1:]
+			break
+		}
+	}
+	if utils.In(pattern, patternValues) == -1 {
+		return ErrIncorrectArgumentValue
+	} else if _, ok := utils.ParseOptions(rest); rest != "" && !ok {
+		return ErrIncorrectArgumentFormat
+	}
+	return nil
+}
+
 // Returns the Flag name and the value of the flags with format: --<flag_name>=<value>
 func getFlagNameAndValue(prefix, argument string) (flagName string, flagValue string, err error) {
 	// Escape case when prefix has more length than argument
@@ -179,6 +1161,8 @@ func PrintHelp() {
 		fmt.Println("The commands are:")
 		fmt.Println("   header    prints bitmap file header information; add --help flag to get detailed information")
 		fmt.Println("   apply     applies processing to the image and saves it to the file, add --help flag to get detailed information")
+		fmt.Println("   thumbnail creates thumbnails of several images in parallel, add --help flag to get detailed information")
+		fmt.Println("   new       creates the image of solid color, gradient, noise or test pattern, add --help flag to get detailed information")
 	} else if Command == "header" {
 		fmt.Println("	bitmap header <source_file>")
 		fmt.Println()
@@ -199,7 +1183,8 @@ func PrintHelp() {
 		fmt.Println()
 		fmt.Println("The options of apply are:")
 		fmt.Println("	--mirror : mirrors a bitmap image either horizontally or vertically; several mirrors may be applied in the provided sequence")
-		fmt.Println("		possible values of --mirror: horizontal, h, horizontally, hor; vertical, v, vertically, ver")
+		fmt.Println("		possible values of --mirror: horizontal, h, horizontally, hor; vertical, v, vertically, ver;")
+		fmt.Println("		transpose, diagonal (flips along the diagonal from the top left corner); transverse, anti-diagonal (from the top right corner)")
 		fmt.Println(" 		usage example: ./bitmap apply --mirror=horizontal sample.bmp sample-mirrored-horizontal.bmp")
 		fmt.Println()
 		fmt.Println("	--filter : applies some image filter on image; several filters may be applied in the provided sequence")
@@ -207,11 +1192,26 @@ func PrintHelp() {
 		fmt.Println("		- blue 		: filter retains only the blue channel")
 		fmt.Println("		- red  		: filter retains only the red channel")
 		fmt.Println("		- green		: filter retains only the green channel")
-		fmt.Println("		- grayscale	: filter converts the image to grayscale")
+		fmt.Println("		- grayscale	: filter converts the image to grayscale; method may be added after colon: grayscale:<method>")
+		fmt.Println("			methods: rec601 (default), rec709, average, lightness, luminosity (in linear light), red, green, blue (single channel)")
 		fmt.Println("		- negative 	: applies a negative filter")
 		fmt.Println("		- sepia		: applies a reddish brown color effect")
 		fmt.Println("		- pixelate 	: apply a pixelation effect, option pixelates the image with a block of 20 pixels by default")
 		fmt.Println("		- blur 		: applies a blur effect")
+		fmt.Println("		- threshold	: binarizes the image to black and white, threshold is set after colon:")
+		fmt.Println("			threshold:<0-255>			fixed luma threshold")
+		fmt.Println("			threshold:otsu				automatic threshold by Otsu's method")
+		fmt.Println("			threshold:mean:<radius>:<constant>	adaptive threshold by the local mean minus constant (7 and 5 by default)")
+		fmt.Println("			threshold:gaussian:<radius>:<constant>	adaptive threshold by the local gaussian weighted mean")
+		fmt.Println("		- posterize	: reduces every channel to N levels: posterize:<2-256> (4 by default)")
+		fmt.Println("		- solarize	: inverts color values above the threshold: solarize:<0-255> (128 by default)")
+		fmt.Println("		- median	: replaces pixels with the median of neighborhood: median:<radius> (2 by default)")
+		fmt.Println("		- mode		: replaces pixels with the most frequent value of neighborhood: mode:<radius> (2 by default)")
+		fmt.Println("		- bilateral	: edge preserving smoothing: bilateral:<radius up to 25>:<strength> (2 and 30 by default)")
+		fmt.Println("		- nlm		: non-local means denoising: nlm:<search radius up to 15>:<strength> (5 and 10 by default)")
+		fmt.Println("		- dither	: reduces colors to the palette with dithering: dither:<method>:<palette>")
+		fmt.Println("			methods: floyd-steinberg, atkinson, jarvis-judice-ninke, sierra, bayer, none (nearest color)")
+		fmt.Println("			palettes: mono (default), gray4, gray16, gray256, win16, web216, rgb555")
 		fmt.Println("		usage example: ./bitmap apply --filter=blur sample.bmp sample-filtered-blur.bmp")
 		fmt.Println()
 		fmt.Println("	--rotate : rotates a bitmap image by a specified angle; several rotates may be applied in the provided sequence")
@@ -230,6 +1230,224 @@ func PrintHelp() {
 		fmt.Println("		crop flag accepts either 2 or 4 values in pixels")
 		fmt.Println("		flag format: --crop=OffsetX-OffsetY-Width-Height, Width and Height are optional")
 		fmt.Println("		usage example: ./bitmap apply --crop=20-20-100-100 sample.bmp sample-cropped-20-20-80-80.bmp")
+		fmt.Println()
+		fmt.Println("	--depth : sets the color pallete of the saved image")
+		fmt.Println("		possible values of --depth:")
+		fmt.Println("		- 1 	: 1 bit monochrome image")
+		fmt.Println("		- 4 	: 4 bit image with standard 16 colors")
+		fmt.Println("		- 8 	: 8 bit grayscale palettized image")
+		fmt.Println("		- 16 	: 16 bit image with 5 bits per channel")
+		fmt.Println("		- 24 	: 24 bit image (default)")
+		fmt.Println("		dithering method may be added after colon: <bits>:<method>, methods are the same as of dither filter")
+		fmt.Println("		usage example: ./bitmap apply --filter=grayscale:luminosity --depth=8 sample.bmp sample-gray.bmp")
+		fmt.Println("		usage example: ./bitmap apply --depth=1:floyd-steinberg sample.bmp sample-mono.bmp")
+		fmt.Println()
+		fmt.Println("	--quantize : reduces the image to an optimized palette of at most N colors and saves it palettized")
+		fmt.Println("		options of --quantize are separated by comma:")
+		fmt.Println("		- colors 	: number of colors from 2 to 256 (256 by default)")
+		fmt.Println("		- method 	: median-cut (default), octree, kmeans")
+		fmt.Println("		- palette 	: file with the palette to quantize to, either GIMP .gpl or the list of hex colors rrggbb")
+		fmt.Println("		- dither 	: dithering method, same as of dither filter (none by default)")
+		fmt.Println("		usage example: ./bitmap apply --quantize=colors:16,method=octree sample.bmp sample-16-colors.bmp")
+		fmt.Println("		usage example: ./bitmap apply --quantize=palette:palette.gpl,dither=floyd-steinberg sample.bmp sample-palette.bmp")
+		fmt.Println()
+		fmt.Println("	--morph : applies morphological operation: --morph=<operation>:<options>; several operations may be applied in the provided sequence")
+		fmt.Println("		operations: erode, dilate, open, close, gradient, tophat, blackhat")
+		fmt.Println("		options of --morph are separated by comma:")
+		fmt.Println("		- element 	: structuring element: square (default), cross, disk or custom rows of 0 and 1 separated by dash, e.g. 010-111-010")
+		fmt.Println("		- radius 	: radius of square, cross and disk elements from 1 to 50 (1 by default)")
+		fmt.Println("		- iterations 	: number of erosions and dilations, they stop once the image no longer changes (1 by default)")
+		fmt.Println("		- mode 		: gray (default) or binary, binary mode binarizes the image by Otsu's threshold first")
+		fmt.Println("		usage example: ./bitmap apply --morph=open:element=disk,radius=2,mode=binary scan.bmp scan-clean.bmp")
+		fmt.Println()
+		fmt.Println("	--resize : changes dimensions of the image: --resize=<width>x<height>[:<options>]")
+		fmt.Println("		zero width or height is calculated from the other one preserving aspect ratio")
+		fmt.Println("		resized image can't have more than 67108864 pixels (8192x8192), the same limit applies to --scale")
+		fmt.Println("		options of --resize are separated by comma:")
+		fmt.Println("		- filter 	: nearest, bilinear, bicubic (default), lanczos3, area (averaging on downscale)")
+		fmt.Println("		- mode 		: stretch (default) ignores aspect ratio, fit keeps image inside of the size,")
+		fmt.Println("			  letterbox fits image and fills the rest with color, fill covers the size and crops the rest,")
+		fmt.Println("			  seam is content-aware resize which removes or inserts the least noticeable seams")
+		fmt.Println("		- color 	: color of letterbox in format rrggbb (000000 by default)")
+		fmt.Println("		- protect 	: BMP mask of the same size for seam mode, light pixels are kept")
+		fmt.Println("		- remove 	: BMP mask of the same size for seam mode, light pixels are removed first")
+		fmt.Println("		usage example: ./bitmap apply --resize=800x600:mode=letterbox,filter=lanczos3 sample.bmp sample-800x600.bmp")
+		fmt.Println("		usage example: ./bitmap apply --resize=400x0:mode=seam,protect=faces.bmp sample.bmp sample-banner.bmp")
+		fmt.Println()
+		fmt.Println("	--scale : changes dimensions of the image by the percent preserving aspect ratio: --scale=<percent>%[:filter=<filter>]")
+		fmt.Println("		usage example: ./bitmap apply --scale=50%:filter=area sample.bmp sample-half.bmp")
+		fmt.Println()
+		fmt.Println("	--color-matrix : transforms colors by the matrix 4x5: [R G B A] = M * [R G B A 1], alpha of 24 bit image is 255")
+		fmt.Println("		value is either 20 comma separated numbers of matrix rows, offsets are in range [0, 255], or preset name:")
+		fmt.Println("		identity, red, green, blue, sepia, negative, grayscale, polaroid, kodachrome, vintage")
+		fmt.Println("		usage example: ./bitmap apply --color-matrix=0,0,1,0,0,0,1,0,0,0,1,0,0,0,0,0,0,0,1,0 sample.bmp sample-swapped.bmp")
+		fmt.Println()
+		fmt.Println("	--channels : swaps or extracts channels, 3 letters set the sources of red, green and blue channels")
+		fmt.Println("		letters: r, g, b, 0 (black), 1 (full value)")
+		fmt.Println("		usage example: ./bitmap apply --channels=bgr sample.bmp sample-rgb.bmp")
+		fmt.Println("		usage example: ./bitmap apply --channels=ggg sample.bmp sample-green-gray.bmp")
+		fmt.Println()
+		fmt.Println("	--lut : applies 1D or 3D lookup table of Adobe/Resolve .cube file: --lut=<file>[:<interpolation>]")
+		fmt.Println("		interpolation of 3D table: tetrahedral (default) or trilinear")
+		fmt.Println("		usage example: ./bitmap apply --lut=look.cube:trilinear sample.bmp sample-graded.bmp")
+		fmt.Println()
+		fmt.Println("	--pad : adds margins to the sides of image: --pad=<top>,<right>,<bottom>,<left>[:<fill>] or --pad=<margin>[:<fill>] for all sides")
+		fmt.Println("		fill of the new area:")
+		fmt.Println("		- rrggbb 	: solid color (000000 by default)")
+		fmt.Println("		- edge 		: replicates the edge pixels")
+		fmt.Println("		- mirror 	: reflects the image")
+		fmt.Println("		usage example: ./bitmap apply --pad=10,20,10,20:ffffff sample.bmp sample-padded.bmp")
+		fmt.Println()
+		fmt.Println("	--border : frames the image with the border of the same width on every side: --border=<width>[:<fill>], fill is the same as of --pad")
+		fmt.Println("		usage example: ./bitmap apply --border=5:ffffff --border=20:000000 sample.bmp sample-framed.bmp")
+		fmt.Println()
+		fmt.Println("	--canvas : changes the canvas size keeping the image at the anchor: --canvas=<width>x<height>[:<options>]")
+		fmt.Println("		parts of image outside of smaller canvas are cut, canvas of --pad, --border and --canvas can't have more than 67108864 pixels")
+		fmt.Println("		options of --canvas are separated by comma:")
+		fmt.Println("		- anchor 	: top-left, top, top-right, left, center (default), right, bottom-left, bottom, bottom-right")
+		fmt.Println("		- color 	: fill of the new area, same as of --pad")
+		fmt.Println("		usage example: ./bitmap apply --canvas=1000x1000:anchor=top,color=mirror sample.bmp sample-square.bmp")
+		fmt.Println()
+		fmt.Println("	--affine : transforms the image by the affine matrix keeping its size: --affine=<a>,<b>,<c>,<d>,<e>,<f>[:<options>]")
+		fmt.Println("		matrix maps source pixel x, y from the top left corner to a*x + b*y + c, d*x + e*y + f")
+		fmt.Println("		or transform parameters applied around the image center in order scale, shear, rotate, translate: --affine=<options>")
+		fmt.Println("		- scale 	: <s> or <sx>x<sy>")
+		fmt.Println("		- shear 	: <kx>x<ky>")
+		fmt.Println("		- rotate 	: angle in degrees clockwise")
+		fmt.Println("		- translate 	: <tx>x<ty> in pixels")
+		fmt.Println("		sampling options:")
+		fmt.Println("		- filter 	: nearest, bilinear (default), bicubic")
+		fmt.Println("		- color 	: fill of pixels outside of image: rrggbb (000000 by default), edge or mirror")
+		fmt.Println("		usage example: ./bitmap apply --affine=scale=1.2,shear=0.1x0,filter=bicubic,color=ffffff sample.bmp sample-sheared.bmp")
+		fmt.Println("		usage example: ./bitmap apply --affine=1,0,-50,0,1,20 sample.bmp sample-moved.bmp")
+		fmt.Println()
+		fmt.Println("	--perspective : maps the quadrilateral of 4 points to the whole image, corrects keystone distortion:")
+		fmt.Println("		--perspective=<x1>,<y1>,<x2>,<y2>,<x3>,<y3>,<x4>,<y4>[:<options>]")
+		fmt.Println("		points are the top left, top right, bottom right and bottom left corners of quadrilateral")
+		fmt.Println("		options are filter and color as of --affine and size=<width>x<height> of the result (size of image by default)")
+		fmt.Println("		usage example: ./bitmap apply --perspective=120,80,900,60,980,1250,40,1300:size=850x1100,filter=bicubic photo.bmp page.bmp")
+		fmt.Println()
+		fmt.Println("	--lens : corrects radial lens distortion, pixel at radius r is sampled from the radius r * (1 + k1*r^2 + k2*r^4)")
+		fmt.Println("		radius is normalized by the half of the smaller side, options of --lens are separated by comma:")
+		fmt.Println("		- k1, k2 	: distortion coefficients (0 by default), negative k1 corrects barrel distortion, positive k1 corrects pincushion")
+		fmt.Println("		- center 	: optical center <x>x<y> in pixels (image center by default)")
+		fmt.Println("		- filter, color : sampling filter and fill of pixels outside of image as of --affine")
+		fmt.Println("		usage example: ./bitmap apply --lens=k1=-0.12,k2=0.02,filter=bicubic camera.bmp camera-straight.bmp")
+		fmt.Println()
+		fmt.Println("	--vignette : darkens or brightens the image towards corners, light is multiplied by 1 - strength * (r / radius)^2")
+		fmt.Println("		radius is normalized by the half of the diagonal, options of --vignette are separated by comma:")
+		fmt.Println("		- strength 	: required, from -1 to 1, positive creates vignette, negative corrects vignette of the same positive strength")
+		fmt.Println("		- radius 	: radius where the falloff reaches its strength (1 by default, the corners)")
+		fmt.Println("		- center 	: center <x>x<y> in pixels (image center by default)")
+		fmt.Println("		usage example: ./bitmap apply --vignette=strength=-0.3 camera.bmp camera-flat.bmp")
+		fmt.Println()
+		fmt.Println("	--overlay : composites the image of BMP file onto the image: --overlay=<file>[:<options>]")
+		fmt.Println("		alpha channel of 32 bit overlay is respected, options of --overlay are separated by comma:")
+		fmt.Println("		- anchor 	: position of overlay as of --canvas (top-left by default)")
+		fmt.Println("		- x, y 		: offsets in pixels away from the anchored sides (0 by default)")
+		fmt.Println("		- opacity 	: from 0 to 1 (1 by default)")
+		fmt.Println("		- tile 		: true repeats the overlay over the whole image (false by default)")
+		fmt.Println("		- mode 		: blend mode: normal (default), multiply, screen, overlay, soft-light, hard-light,")
+		fmt.Println("			  difference, darken, lighten, add, subtract")
+		fmt.Println("		usage example: ./bitmap apply --overlay=logo.bmp:anchor=bottom-right,x=10,y=10,opacity=0.5 sample.bmp sample-stamped.bmp")
+		fmt.Println("		usage example: ./bitmap apply --overlay=texture.bmp:mode=soft-light,tile=true sample.bmp sample-textured.bmp")
+		fmt.Println()
+		fmt.Println("	--region : restricts the following operations to the region: --region=<shape>:<parameters>[:<options>] or --region=none")
+		fmt.Println("		results of operations are mixed with the image before them by the mask of region, none removes the region,")
+		fmt.Println("		operations changing the size of image can't be restricted, shapes of region:")
+		fmt.Println("		- rect 		: x,y,width,height as of --crop")
+		fmt.Println("		- ellipse 	: x,y,rx,ry")
+		fmt.Println("		- mask 		: grayscale BMP file, white is selected, it is stretched to the size of image")
+		fmt.Println("		- wand 		: x,y selects the region of similar color as of --flood-fill")
+		fmt.Println("		options of --region are separated by comma:")
+		fmt.Println("		- feather 	: width of soft edge in pixels (0 by default)")
+		fmt.Println("		- invert 	: true selects everything outside of the shape (false by default)")
+		fmt.Println("		- tolerance, connectivity : options of wand as of --flood-fill")
+		fmt.Println("		usage example: ./bitmap apply --region=rect:120,40,80,100 --filter=pixelate --region=none --filter=sepia sample.bmp sample-anonymized.bmp")
+		fmt.Println("		usage example: ./bitmap apply --region=ellipse:240,180,120,90:feather=20,invert=true --filter=blur sample.bmp sample-portrait.bmp")
+		fmt.Println()
+		fmt.Println("	--flood-fill : fills the region connected to the pixel by the color: --flood-fill=<x>,<y>[:<options>]")
+		fmt.Println("		region has pixels which channels differ from the channels of pixel x,y by no more than tolerance")
+		fmt.Println("		options of --flood-fill are separated by comma:")
+		fmt.Println("		- color 	: fill color in hex (ffffff by default)")
+		fmt.Println("		- tolerance 	: from 0 to 255 (10 by default)")
+		fmt.Println("		- connectivity 	: 4 joins pixels by sides, 8 joins them by corners too (4 by default)")
+		fmt.Println("		usage example: ./bitmap apply --flood-fill=0,0:color=00ff00,tolerance=40,connectivity=8 sample.bmp sample-filled.bmp")
+		fmt.Println()
+		fmt.Println("	--draw : draws the shape: --draw=<shape>:<coordinates>[:<options>], coordinates are separated by comma:")
+		fmt.Println("		- line, arrow 	: x0,y0,x1,y1, arrow has the head at x1,y1")
+		fmt.Println("		- rect 		: x,y,width,height as of --crop")
+		fmt.Println("		- circle 	: x,y,radius")
+		fmt.Println("		- ellipse 	: x,y,rx,ry")
+		fmt.Println("		- polygon 	: x0,y0,x1,y1,x2,y2,... of at least 3 points")
+		fmt.Println("		options of --draw are separated by comma:")
+		fmt.Println("		- color 	: color of stroke or fill in hex (ffffff by default)")
+		fmt.Println("		- width 	: stroke width in pixels (1 by default)")
+		fmt.Println("		- fill 		: true paints the inside of rect, circle, ellipse and polygon (false by default)")
+		fmt.Println("		- aa 		: true draws anti-aliased edges, thin lines are drawn by Wu's algorithm (false by default)")
+		fmt.Println("		usage example: ./bitmap apply --draw=rect:10,10,50,50:color=ff0000,width=2 --draw=arrow:120,120,65,65:color=ff0000,aa=true sample.bmp sample-annotated.bmp")
+		fmt.Println()
+		fmt.Println("	--text : draws the text by the builtin 8x8 bitmap font: --text=<text>[:<options>]")
+		fmt.Println("		\\n starts a new line, options go after the last colon, so text may contain colons, options are separated by comma:")
+		fmt.Println("		- anchor 	: position of text block as of --canvas (top-left by default)")
+		fmt.Println("		- x, y 		: offsets in pixels away from the anchored sides (0 by default)")
+		fmt.Println("		- color 	: color of text in hex (ffffff by default)")
+		fmt.Println("		- scale 	: size of font pixel in image pixels (1 by default)")
+		fmt.Println("		- background 	: color of box drawn behind the text in hex (no box by default)")
+		fmt.Println("		- font 		: BDF font file used instead of the builtin font")
+		fmt.Println("		usage example: ./bitmap apply --text=\"2026-10-19 12:30:anchor=bottom-right,x=8,y=8,scale=2,background=000000\" sample.bmp sample-stamped.bmp")
+		fmt.Println("		usage example: ./bitmap apply --text=\"Line one\\nLine two:font=ter-u16n.bdf,color=ff0000\" sample.bmp sample-labeled.bmp")
+		fmt.Println()
+		fmt.Println("	--trim : crops uniform borders of the color which the most image corners match within the tolerance: --trim or --trim=<tolerance>")
+		fmt.Println("		tolerance is the difference of channel value from 0 to 255 still considered as border (10 by default)")
+		fmt.Println("		usage example: ./bitmap apply --trim=30 --verbose receipt.bmp receipt-trimmed.bmp")
+		fmt.Println()
+		fmt.Println("	--deskew : detects the skew angle of text lines by projection profile and rotates the image back, prints the detected angle")
+		fmt.Println("		--deskew or --deskew=<maximum angle from 1 to 45 degrees> (10 by default), uncovered pixels get the border color")
+		fmt.Println("		usage example: ./bitmap apply --deskew --trim scan.bmp scan-straight.bmp")
+		fmt.Println()
+		fmt.Println("	--verbose : prints details of processing, e.g. the box detected by --trim")
 		fmt.Println("	<source_file> <output_file> must go last in the arguments list")
+	} else if Command == "thumbnail" {
+		fmt.Println("   bitmap thumbnail [options] <source_files...> <output_dir>")
+		fmt.Println()
+		fmt.Println("Description:")
+		fmt.Println("   Downsamples every image to fit inside of the square preserving aspect ratio and saves it to the output directory,")
+		fmt.Println("   images are processed in parallel, smaller images are not enlarged")
+		fmt.Println()
+		fmt.Println("The options of thumbnail are:")
+		fmt.Println("	--size 	  : side of the square in pixels (256 by default)")
+		fmt.Println("	--sharpen : amount of unsharp mask applied after downsampling, e.g. 0.5 (0 by default, no sharpening)")
+		fmt.Println("	--name 	  : template of thumbnail file name ({name}_thumb.bmp by default), placeholders:")
+		fmt.Println("		{name} 	: source file name without extension")
+		fmt.Println("		{index} : position of source file in the arguments list starting from 1")
+		fmt.Println("		{size} 	: value of --size")
+		fmt.Println("	--jobs 	  : number of images processed at the same time (number of CPUs by default)")
+		fmt.Println("	usage example: ./bitmap thumbnail --size=128 --sharpen=0.5 --name=thumb-{index}.bmp photos/*.bmp thumbs")
+		fmt.Println("	<source_files...> <output_dir> must go last in the arguments list")
+	} else if Command == "new" {
+		fmt.Println("   bitmap new [options] <output_file>")
+		fmt.Println()
+		fmt.Println("Description:")
+		fmt.Println("   Creates the image from scratch, e.g. fixtures of tests and backgrounds")
+		fmt.Println()
+		fmt.Println("The options of new are:")
+		fmt.Println("	--size 	  : <width>x<height> in pixels (256x256 by default), up to 67108864 pixels (8192x8192)")
+		fmt.Println("	--depth   : color pallete of the saved image as of apply --depth (24 by default)")
+		fmt.Println("	--pattern : content of image: --pattern=<pattern>[:<options>] (solid black by default), patterns:")
+		fmt.Println("		- solid 	: one color")
+		fmt.Println("		- linear 	: linear gradient, angle in degrees, 0 goes from left to right, 90 from top to bottom (0 by default)")
+		fmt.Println("		- radial 	: radial gradient, center=<x>x<y> (center of image by default) and radius (to the farthest corner by default)")
+		fmt.Println("		- conic 	: conic gradient around center, angle of its start in degrees (0 by default)")
+		fmt.Println("		- checker 	: checkerboard of two colors, size of cell in pixels (16 by default)")
+		fmt.Println("		- grid 		: lines of the second color every size pixels (16 by default)")
+		fmt.Println("		- noise 	: white noise, seed of random numbers (0 by default)")
+		fmt.Println("		- perlin 	: fractal Perlin noise, seed, scale of cell in pixels (32 by default), octaves from 1 to 8 (4 by default)")
+		fmt.Println("		- bars 		: color bars: white, yellow, cyan, green, magenta, red, blue, black")
+		fmt.Println("		colors=<rrggbb>-<rrggbb>-... option sets the color of solid, two colors of checker and grid")
+		fmt.Println("		or the stops of gradients and noise spread evenly (black and white by default)")
+		fmt.Println("	usage example: ./bitmap new --size=640x480 --pattern=linear:colors=ff0000-ffff00-0000ff,angle=45 gradient.bmp")
+		fmt.Println("	usage example: ./bitmap new --size=512x512 --pattern=perlin:seed=7,scale=64 --depth=8 clouds.bmp")
+		fmt.Println("	<output_file> must go last in the arguments list")
 	}
 }
@@ -11,6 +11,10 @@ func TestParse(t *testing.T) {
 		outputArgs []Argument
 		sourceFile string
 		outputFile string
+		verbose    bool
+		// Thumbnail command
+		sourceFiles []string
+		outputDir   string
 	}
 
 	tests := []testData{
@@ -50,6 +54,14 @@ func TestParse(t *testing.T) {
 			outputFile: "output_file",
 			command:    "apply",
 		},
+		{
+			name:       "Apply command with diagonal mirror flags",
+			args:       []string{"apply", "--mirror=diagonal", "--mirror=transverse", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "mirror", Value: "transpose"}, {Name: "mirror", Value: "transverse"}},
+			sourceFile: "source_file",
+			outputFile: "output_file",
+			command:    "apply",
+		},
 		{
 			name:       "Apply command with filter flag",
 			args:       []string{"apply", "--filter=blur", "source_file", "output_file"},
@@ -90,6 +102,180 @@ func TestParse(t *testing.T) {
 			err:     HelpCommand,
 			command: "header",
 		},
+		{
+			name:       "Apply command with grayscale method",
+			args:       []string{"apply", "--filter=grayscale:luminosity", "--depth=8", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "filter", Value: "grayscale:luminosity"}, {Name: "depth", Value: "8"}},
+			sourceFile: "source_file",
+			outputFile: "output_file",
+			command:    "apply",
+		},
+		{
+			name:    "Apply command with incorrect grayscale method",
+			args:    []string{"apply", "--filter=grayscale:sepia", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with adaptive threshold",
+			args:       []string{"apply", "--filter=threshold:gaussian:10:-2", "--depth=1", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "filter", Value: "threshold:gaussian:10:-2"}, {Name: "depth", Value: "1"}},
+			sourceFile: "source_file",
+			outputFile: "output_file",
+			command:    "apply",
+		},
+		{
+			name:    "Apply command with out of range threshold",
+			args:    []string{"apply", "--filter=threshold:300", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with zero radius of adaptive threshold",
+			args:    []string{"apply", "--filter=threshold:mean:0", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with overflowing threshold",
+			args:    []string{"apply", "--filter=threshold:18446744073709551716", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with dithered depth and dither filter",
+			args:       []string{"apply", "--filter=dither:bayer:web216", "--depth=4:atkinson", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "filter", Value: "dither:bayer:web216"}, {Name: "depth", Value: "4:atkinson"}},
+			sourceFile: "source_file",
+			outputFile: "output_file",
+			command:    "apply",
+		},
+		{
+			name:    "Apply command with incorrect dithering method",
+			args:    []string{"apply", "--depth=1:random", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with quantize",
+			args:       []string{"apply", "--quantize=colors:16,method=octree", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "quantize", Value: "colors:16,method=octree"}},
+			sourceFile: "source_file",
+			outputFile: "output_file",
+			command:    "apply",
+		},
+		{
+			name:    "Apply command with quantize to palette and method",
+			args:    []string{"apply", "--quantize=palette:web.gpl,method=kmeans", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with posterize and solarize",
+			args:       []string{"apply", "--filter=posterize:3", "--filter=solarize", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "filter", Value: "posterize:3"}, {Name: "filter", Value: "solarize"}},
+			sourceFile: "source_file",
+			outputFile: "output_file",
+			command:    "apply",
+		},
+		{
+			name:    "Apply command with not numeric posterize levels",
+			args:    []string{"apply", "--filter=posterize:many", "source_file", "output_file"},
+			err:     ErrNotNumericArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with out of range solarize threshold",
+			args:    []string{"apply", "--filter=solarize:256", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with denoising filters",
+			args:       []string{"apply", "--filter=median:5", "--filter=bilateral:3:20", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "filter", Value: "median:5"}, {Name: "filter", Value: "bilateral:3:20"}},
+			sourceFile: "source_file",
+			outputFile: "output_file",
+			command:    "apply",
+		},
+		{
+			name:    "Apply command with median strength",
+			args:    []string{"apply", "--filter=median:5:20", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with too large radius of non-local means",
+			args:    []string{"apply", "--filter=nlm:1000000000000", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with morphology",
+			args:       []string{"apply", "--morph=open:element=010-111-010,iterations=2", "--morph=tophat", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "morph", Value: "open:element=010-111-010,iterations=2"}, {Name: "morph", Value: "tophat"}},
+			sourceFile: "source_file",
+			outputFile: "output_file",
+			command:    "apply",
+		},
+		{
+			name:    "Apply command with incorrect morphology option",
+			args:    []string{"apply", "--morph=erode:size=3", "source_file", "output_file"},
+			err:     ErrIncorrectOptionName,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with too large morphology radius",
+			args:    []string{"apply", "--morph=dilate:radius=1000000000000", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with color matrix and channels",
+			args:       []string{"apply", "--color-matrix=polaroid", "--channels=bgr", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "color-matrix", Value: "polaroid"}, {Name: "channels", Value: "bgr"}},
+			sourceFile: "source_file",
+			outputFile: "output_file",
+			command:    "apply",
+		},
+		{
+			name:    "Apply command with alpha source of channels",
+			args:    []string{"apply", "--channels=aaa", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with short color matrix",
+			args:    []string{"apply", "--color-matrix=1,0,0", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with resize and scale",
+			args:       []string{"apply", "--resize=800x0:filter=lanczos3", "--scale=50%:filter=area", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "resize", Value: "800x0:filter=lanczos3"}, {Name: "scale", Value: "50%:filter=area"}},
+			sourceFile: "source_file",
+			outputFile: "output_file",
+			command:    "apply",
+		},
+		{
+			name:    "Apply command with incorrect resize mode",
+			args:    []string{"apply", "--resize=800x600:mode=zoom", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with too large resize",
+			args:    []string{"apply", "--resize=100000x100000", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with resize not fitting int",
+			args:    []string{"apply", "--resize=99999999999999999999x0", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
 		{
 			name:       "Several options called",
 			args:       []string{"apply", "--filter=blur", "--rotate=90", "source_file", "output_file"},
@@ -99,6 +285,268 @@ func TestParse(t *testing.T) {
 			sourceFile: "source_file",
 			outputFile: "output_file",
 		},
+		{
+			name:       "Apply command with canvas flags",
+			args:       []string{"apply", "--pad=1,2,3,4:edge", "--border=5:ffffff", "--canvas=100x50:anchor=top-left,color=mirror", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "pad", Value: "1,2,3,4:edge"}, {Name: "border", Value: "5:ffffff"}, {Name: "canvas", Value: "100x50:anchor=top-left,color=mirror"}},
+			command:    "apply",
+			sourceFile: "source_file",
+			outputFile: "output_file",
+		},
+		{
+			name:    "Apply command with incorrect pad",
+			args:    []string{"apply", "--pad=1,2,3", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with incorrect canvas anchor",
+			args:    []string{"apply", "--canvas=100x50:anchor=middle", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with too large canvas",
+			args:    []string{"apply", "--canvas=100000x100000", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with pad not fitting int",
+			args:    []string{"apply", "--pad=0,99999999999999999999,0,0", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with too large border",
+			args:    []string{"apply", "--border=4294967295", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with transform flags",
+			args:       []string{"apply", "--affine=rotate=-15,scale=1.5x1,filter=bicubic", "--affine=1,0,-5,0,1,2.5", "--perspective=0,0,9,1,10,10,-1,9:size=20x20,color=edge", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "affine", Value: "rotate=-15,scale=1.5x1,filter=bicubic"}, {Name: "affine", Value: "1,0,-5,0,1,2.5"}, {Name: "perspective", Value: "0,0,9,1,10,10,-1,9:size=20x20,color=edge"}},
+			command:    "apply",
+			sourceFile: "source_file",
+			outputFile: "output_file",
+		},
+		{
+			name:    "Apply command with perspective of 3 points",
+			args:    []string{"apply", "--perspective=0,0,9,1,10,10", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with lens and vignette flags",
+			args:       []string{"apply", "--lens=k1=-0.2,k2=0.05,center=100x80", "--vignette=strength=-0.4,radius=1.2", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "lens", Value: "k1=-0.2,k2=0.05,center=100x80"}, {Name: "vignette", Value: "strength=-0.4,radius=1.2"}},
+			command:    "apply",
+			sourceFile: "source_file",
+			outputFile: "output_file",
+		},
+		{
+			name:    "Apply command with vignette without strength",
+			args:    []string{"apply", "--vignette=radius=1", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with overlay flag",
+			args:       []string{"apply", "--overlay=logo.bmp:anchor=bottom-right,x=10,y=-5,opacity=0.5,tile=false", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "overlay", Value: "logo.bmp:anchor=bottom-right,x=10,y=-5,opacity=0.5,tile=false"}},
+			command:    "apply",
+			sourceFile: "source_file",
+			outputFile: "output_file",
+		},
+		{
+			name:       "Apply command with overlay blend mode",
+			args:       []string{"apply", "--overlay=texture.bmp:mode=soft-light,tile=true", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "overlay", Value: "texture.bmp:mode=soft-light,tile=true"}},
+			command:    "apply",
+			sourceFile: "source_file",
+			outputFile: "output_file",
+		},
+		{
+			name:    "Apply command with incorrect overlay blend mode",
+			args:    []string{"apply", "--overlay=texture.bmp:mode=burn", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with overlay opacity above 1",
+			args:    []string{"apply", "--overlay=logo.bmp:opacity=2", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with region flags",
+			args:       []string{"apply", "--region=ellipse:240,180,120,90:feather=20,invert=true", "--filter=blur", "--region=none", "--region=wand:0,0:tolerance=30", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "region", Value: "ellipse:240,180,120,90:feather=20,invert=true"}, {Name: "filter", Value: "blur"}, {Name: "region", Value: "none"}, {Name: "region", Value: "wand:0,0:tolerance=30"}},
+			command:    "apply",
+			sourceFile: "source_file",
+			outputFile: "output_file",
+		},
+		{
+			name:    "Apply command with region rect of zero width",
+			args:    []string{"apply", "--region=rect:0,0,0,10", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with tolerance of rect region",
+			args:    []string{"apply", "--region=rect:0,0,10,10:tolerance=5", "source_file", "output_file"},
+			err:     ErrIncorrectOptionName,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with flood fill flag",
+			args:       []string{"apply", "--flood-fill=0,0:color=00ff00,tolerance=40,connectivity=8", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "flood-fill", Value: "0,0:color=00ff00,tolerance=40,connectivity=8"}},
+			command:    "apply",
+			sourceFile: "source_file",
+			outputFile: "output_file",
+		},
+		{
+			name:    "Apply command with flood fill connectivity 6",
+			args:    []string{"apply", "--flood-fill=5,5:connectivity=6", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with flood fill without seed y",
+			args:    []string{"apply", "--flood-fill=5", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentFormat,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with draw flag",
+			args:       []string{"apply", "--draw=rect:10,10,50,50:color=ff0000", "--draw=polygon:0,0,10,0,5,8:fill=true,aa=true", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "draw", Value: "rect:10,10,50,50:color=ff0000"}, {Name: "draw", Value: "polygon:0,0,10,0,5,8:fill=true,aa=true"}},
+			command:    "apply",
+			sourceFile: "source_file",
+			outputFile: "output_file",
+		},
+		{
+			name:    "Apply command with incorrect draw shape",
+			args:    []string{"apply", "--draw=star:10,10,5", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with wrong number of circle coordinates",
+			args:    []string{"apply", "--draw=circle:10,10", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with incorrect draw option",
+			args:    []string{"apply", "--draw=line:0,0,5,5:dash=true", "source_file", "output_file"},
+			err:     ErrIncorrectOptionName,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with text flag",
+			args:       []string{"apply", "--text=12:30:anchor=bottom-right,x=8,scale=2,color=ff0000,background=000000", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "text", Value: "12:30:anchor=bottom-right,x=8,scale=2,color=ff0000,background=000000"}},
+			command:    "apply",
+			sourceFile: "source_file",
+			outputFile: "output_file",
+		},
+		{
+			name:       "Apply command with text containing colons only",
+			args:       []string{"apply", "--text=Time: 12:30", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "text", Value: "Time: 12:30"}},
+			command:    "apply",
+			sourceFile: "source_file",
+			outputFile: "output_file",
+		},
+		{
+			name:    "Apply command with text scale 0",
+			args:    []string{"apply", "--text=label:scale=0", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:    "Apply command with empty text",
+			args:    []string{"apply", "--text=:x=5", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentValue,
+			command: "apply",
+		},
+		{
+			name:       "Apply command with trim, deskew and verbose switches",
+			args:       []string{"apply", "--trim", "--trim=30", "--deskew", "--deskew=5", "--verbose", "source_file", "output_file"},
+			outputArgs: []Argument{{Name: "trim", Value: ""}, {Name: "trim", Value: "30"}, {Name: "deskew", Value: ""}, {Name: "deskew", Value: "5"}},
+			command:    "apply",
+			sourceFile: "source_file",
+			outputFile: "output_file",
+			verbose:    true,
+		},
+		{
+			name:    "Apply command with option without value",
+			args:    []string{"apply", "--filter", "source_file", "output_file"},
+			err:     ErrIncorrectArgumentFormat,
+			command: "apply",
+		},
+		{
+			name:        "Thumbnail command with options",
+			args:        []string{"thumbnail", "--size=128", "--sharpen=0.5", "--name={name}-{size}.bmp", "a.bmp", "b.bmp", "thumbs"},
+			outputArgs:  []Argument{{Name: "size", Value: "128"}, {Name: "sharpen", Value: "0.5"}, {Name: "name", Value: "{name}-{size}.bmp"}},
+			command:     "thumbnail",
+			sourceFiles: []string{"a.bmp", "b.bmp"},
+			outputDir:   "thumbs",
+		},
+		{
+			name:       "Thumbnail command without output directory",
+			args:       []string{"thumbnail", "--size=128", "a.bmp"},
+			err:        ErrIncorrectNumberOfArguments,
+			command:    "thumbnail",
+			outputArgs: []Argument{{Name: "size", Value: "128"}},
+		},
+		{
+			name:    "Thumbnail command with zero size",
+			args:    []string{"thumbnail", "--size=0", "a.bmp", "thumbs"},
+			err:     ErrIncorrectArgumentValue,
+			command: "thumbnail",
+		},
+		{
+			name:       "New command with options",
+			args:       []string{"new", "--size=640x480", "--pattern=linear:colors=ff0000-ffff00-0000ff,angle=45", "--depth=8", "gradient.bmp"},
+			outputArgs: []Argument{{Name: "size", Value: "640x480"}, {Name: "pattern", Value: "linear:colors=ff0000-ffff00-0000ff,angle=45"}, {Name: "depth", Value: "8"}},
+			command:    "new",
+			outputFile: "gradient.bmp",
+		},
+		{
+			name:    "New command with unknown pattern",
+			args:    []string{"new", "--pattern=stripes:colors=000000-ffffff", "stripes.bmp"},
+			err:     ErrIncorrectArgumentValue,
+			command: "new",
+		},
+		{
+			name:    "New command with pattern options without values",
+			args:    []string{"new", "--pattern=grid:size", "grid.bmp"},
+			err:     ErrIncorrectArgumentFormat,
+			command: "new",
+		},
+		{
+			name:       "New command without output file",
+			args:       []string{"new", "--size=10x10"},
+			err:        ErrIncorrectNumberOfArguments,
+			command:    "new",
+			outputArgs: []Argument{{Name: "size", Value: "10x10"}},
+		},
+		{
+			name:    "New command with too large size",
+			args:    []string{"new", "--size=100000x100000", "huge.bmp"},
+			err:     ErrIncorrectArgumentValue,
+			command: "new",
+		},
+		{
+			name:    "Thumbnail command with constant name template",
+			args:    []string{"thumbnail", "--name=thumb.bmp", "a.bmp", "thumbs"},
+			err:     ErrIncorrectArgumentValue,
+			command: "thumbnail",
+		},
 	}
 
 	for _, test := range tests {
@@ -112,6 +560,10 @@ func TestParse(t *testing.T) {
 				t.Errorf("Parse() sourceFile = %v, want %v", SourceFile, test.sourceFile)
 			} else if OutputFile != test.outputFile {
 				t.Errorf("Parse() outputFile = %v, want %v", OutputFile, test.outputFile)
+			} else if Verbose != test.verbose {
+				t.Errorf("Parse() verbose = %v, want %v", Verbose, test.verbose)
+			} else if OutputDir != test.outputDir || len(SourceFiles) != len(test.sourceFiles) {
+				t.Errorf("Parse() sourceFiles, outputDir = %v, %v, want %v, %v", SourceFiles, OutputDir, test.sourceFiles, test.outputDir)
 			} else if len(test.outputArgs) != len(Arguments) {
 				t.Errorf("Parse() Arguments = %v, want %v", Arguments, test.outputArgs)
 			}
@@ -127,6 +579,9 @@ func TestParse(t *testing.T) {
 			Command = ""
 			SourceFile = ""
 			OutputFile = ""
+			Verbose = false
+			SourceFiles = nil
+			OutputDir = ""
 		})
 	}
 }
@@ -3,10 +3,21 @@ package main
 import (
 	"bitmap/bmp"
 	"bitmap/flag"
+	"bitmap/utils"
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
+// Default options of thumbnail command
+const (
+	thumbnailSize = 256
+	thumbnailName = "{name}_thumb.bmp"
+)
+
+// Default side of image of new command
+const newImageSize = 256
+
 func main() {
 	// Flag proccessing
 	err := flag.Parse(os.Args[1:])
@@ -20,6 +31,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	if flag.Command == "thumbnail" {
+		if !makeThumbnails() {
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.Command == "new" {
+		if !makeImage() {
+			os.Exit(1)
+		}
+		return
+	}
+
 	bmpFile, err := bmp.Load(flag.SourceFile)
 	if err != nil {
 		if err == bmp.ErrNon24BitImageNotSupported {
@@ -40,9 +64,21 @@ func main() {
 			fmt.Fprintf(os.Stderr, "File: %s is not 24 bit color pallete", flag.SourceFile)
 			os.Exit(1)
 		}
-		// Arguments proccessing
+		// Arguments proccessing, operations after region are mixed with the image before them by the mask of region
+		var region [][]byte
 		for _, arg := range flag.Arguments {
+			original := bmpFile
+			if region != nil && arg.Name != "region" {
+				original = bmpFile.Clone()
+			}
+
 			switch arg.Name {
+			case "region":
+				region, err = bmpFile.Region(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while selecting region of the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
 			case "mirror":
 				err := bmpFile.Mirror(arg.Value)
 				if err != nil {
@@ -55,13 +91,247 @@ func main() {
 					fmt.Fprintf(os.Stderr, "Error while Filtering the BMP image: %s.\n", err)
 					os.Exit(1)
 				}
+			case "depth":
+				err := bmpFile.Depth(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while changing the depth of BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "quantize":
+				err := bmpFile.Quantize(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while Quantizing the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "morph":
+				err := bmpFile.Morphology(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while applying morphology to the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "color-matrix":
+				err := bmpFile.ColorMatrix(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while applying color matrix to the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "channels":
+				err := bmpFile.Channels(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while swapping channels of the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "lut":
+				err := bmpFile.Lut(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while applying LUT to the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "resize":
+				err := bmpFile.Resize(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while Resizing the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "scale":
+				err := bmpFile.Scale(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while Scaling the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "pad":
+				err := bmpFile.Pad(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while Padding the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "border":
+				err := bmpFile.Border(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while adding border to the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "canvas":
+				err := bmpFile.Canvas(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while changing canvas of the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "affine":
+				err := bmpFile.Affine(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while transforming the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "perspective":
+				err := bmpFile.Perspective(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while correcting perspective of the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "lens":
+				err := bmpFile.Lens(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while correcting lens distortion of the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "vignette":
+				err := bmpFile.Vignette(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while applying vignette to the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "overlay":
+				err := bmpFile.Overlay(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while overlaying the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "flood-fill":
+				err := bmpFile.FloodFill(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while filling the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "draw":
+				err := bmpFile.Draw(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while drawing on the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "text":
+				err := bmpFile.Text(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while drawing text on the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+			case "trim":
+				x, y, width, height, err := bmpFile.Trim(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while Trimming the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+				if flag.Verbose {
+					fmt.Printf("Trim: content box %dx%d at %d,%d\n", width, height, x, y)
+				}
+			case "deskew":
+				angle, err := bmpFile.Deskew(arg.Value)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error while Deskewing the BMP image: %s.\n", err)
+					os.Exit(1)
+				}
+				fmt.Printf("Deskew: detected angle %.2f degrees\n", angle)
 			case "crop":
 				return
 			case "rotate":
 				return
 			}
+
+			if original != bmpFile {
+				if err := bmpFile.Restrict(original, region); err != nil {
+					fmt.Fprintf(os.Stderr, "Error while restricting %s to region of the BMP image: %s.\n", arg.Name, err)
+					os.Exit(1)
+				}
+			}
 		}
 	}
 
 	bmpFile.Save(flag.OutputFile)
 }
+
+// makeImage creates the image of pattern and saves it to the output file, returns false if it failed
+func makeImage() bool {
+	width, height, pattern, depth := newImageSize, newImageSize, "solid", ""
+	for _, arg := range flag.Arguments {
+		switch arg.Name {
+		case "size":
+			sizes := utils.Split(arg.Value, "x")
+			width, _ = utils.Atoi(sizes[0])
+			height, _ = utils.Atoi(sizes[1])
+		case "pattern":
+			pattern = arg.Value
+		case "depth":
+			depth = arg.Value
+		}
+	}
+
+	bmpFile, err := bmp.New(width, height)
+	if err == nil {
+		err = bmpFile.Generate(pattern)
+	}
+	if err == nil && depth != "" {
+		err = bmpFile.Depth(depth)
+	}
+	if err == nil {
+		err = bmpFile.Save(flag.OutputFile)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while creating the BMP image: %s.\n", err)
+		return false
+	}
+	return true
+}
+
+// makeThumbnails creates thumbnails of source files in parallel, errors are reported for every file,
+// returns false if any file failed
+func makeThumbnails() bool {
+	size, sharpen, template, jobs := thumbnailSize, 0., thumbnailName, 0
+	for _, arg := range flag.Arguments {
+		switch arg.Name {
+		case "size":
+			size, _ = utils.Atoi(arg.Value)
+		case "sharpen":
+			sharpen, _ = utils.ParseFloat(arg.Value)
+		case "name":
+			template = arg.Value
+		case "jobs":
+			jobs, _ = utils.Atoi(arg.Value)
+		}
+	}
+
+	// Output files are named before the processing, so sources with the same base name don't overwrite each other
+	outputs := make([]string, len(flag.SourceFiles))
+	sources := make(map[string]string)
+	for idx, source := range flag.SourceFiles {
+		name := filepath.Base(source)
+		name = name[:len(name)-len(filepath.Ext(name))]
+
+		output := utils.Replace(template, "{name}", name)
+		output = utils.Replace(output, "{index}", fmt.Sprint(idx+1))
+		output = utils.Replace(output, "{size}", fmt.Sprint(size))
+		outputs[idx] = filepath.Join(flag.OutputDir, output)
+
+		if previous, ok := sources[outputs[idx]]; ok {
+			fmt.Fprintf(os.Stderr, "Error while making thumbnails: %s and %s have the same output file %s, add {index} to the name template.\n", previous, source, outputs[idx])
+			return false
+		}
+		sources[outputs[idx]] = source
+	}
+
+	if err := os.MkdirAll(flag.OutputDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error while creating the output directory: %s.\n", err)
+		return false
+	}
+
+	errs := utils.Parallel(len(flag.SourceFiles), jobs, func(idx int) error {
+		bmpFile, err := bmp.Load(flag.SourceFiles[idx])
+		if err != nil {
+			return err
+		} else if bmpFile.GetPixelNumber() != 24 {
+			return bmp.ErrNon24BitImageNotSupported
+		}
+		if err := bmpFile.Thumbnail(size, sharpen); err != nil {
+			return err
+		}
+		return bmpFile.Save(outputs[idx])
+	})
+
+	ok := true
+	for idx, err := range errs {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error while making thumbnail of %s: %s.\n", flag.SourceFiles[idx], err)
+			ok = false
+		}
+	}
+	return ok
+}
@@ -1,5 +1,11 @@
 package utils
 
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
 func In(s string, arr []string) int {
 	for idx, str := range arr {
 		if str == s {
@@ -64,3 +70,202 @@ func BinPow(x, n uint32) uint32 {
 		return BinPow(x, n/2) * BinPow(x, n/2)
 	}
 }
+
+// Converts the decimal string with optional sign to int
+func Atoi(s string) (int, bool) {
+	sign := 1
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+	if len(s) == 0 || !IsNumeric(s) {
+		return 0, false
+	}
+
+	// Values not fitting into int are rejected
+	const maxInt = int(^uint(0) >> 1)
+	res := 0
+	for _, char := range s {
+		digit := int(char - '0')
+		if res > (maxInt-digit)/10 {
+			return 0, false
+		}
+		res = res*10 + digit
+	}
+	return sign * res, true
+}
+
+// Splits the string by whitespace characters, empty fields are dropped
+func Fields(s string) []string {
+	res := []string{}
+
+	start := -1
+	for idx := 0; idx < len(s); idx++ {
+		isSpace := s[idx] == ' ' || s[idx] == '\t' || s[idx] == '\r' || s[idx] == '\n'
+		if isSpace && start != -1 {
+			res = append(res, s[start:idx])
+			start = -1
+		} else if !isSpace && start == -1 {
+			start = idx
+		}
+	}
+	if start != -1 {
+		res = append(res, s[start:])
+	}
+
+	return res
+}
+
+// Parses the list of options with format: <key>=<value>,<key>:<value>,...
+// the first '=' or ':' separates the key from the value
+func ParseOptions(s string) (map[string]string, bool) {
+	options := make(map[string]string)
+
+	for _, option := range Split(s, ",") {
+		sepIdx := -1
+		for idx := range option {
+			if option[idx] == '=' || option[idx] == ':' {
+				sepIdx = idx
+				break
+			}
+		}
+		if sepIdx <= 0 || sepIdx == len(option)-1 {
+			return nil, false
+		}
+		if _, ok := options[option[:sepIdx]]; ok {
+			return nil, false
+		}
+		options[option[:sepIdx]] = option[sepIdx+1:]
+	}
+
+	return options, true
+}
+
+// Parses the color with format: [#]rrggbb
+func ParseHexColor(s string) (red, green, blue byte, ok bool) {
+	if len(s) > 0 && s[0] == '#' {
+		s = s[1:]
+	}
+	if len(s) != 6 {
+		return 0, 0, 0, false
+	}
+
+	var channels [3]byte
+	for idx := 0; idx < 6; idx++ {
+		var digit byte
+		switch char := s[idx]; {
+		case char >= '0' && char <= '9':
+			digit = char - '0'
+		case char >= 'a' && char <= 'f':
+			digit = char - 'a' + 10
+		case char >= 'A' && char <= 'F':
+			digit = char - 'A' + 10
+		default:
+			return 0, 0, 0, false
+		}
+		channels[idx/2] = channels[idx/2]<<4 | digit
+	}
+
+	return channels[0], channels[1], channels[2], true
+}
+
+// Converts the decimal string with optional sign, fraction part and exponent to float64
+func ParseFloat(s string) (float64, bool) {
+	// Exponent part: <mantissa>e<exponent>
+	for idx := range s {
+		if s[idx] == 'e' || s[idx] == 'E' {
+			mantissa, ok := ParseFloat(s[:idx])
+			exponent, isInt := Atoi(s[idx+1:])
+			if !ok || !isInt {
+				return 0, false
+			}
+			if mantissa == 0 {
+				return 0, true
+			}
+			// Values out of the float range are rejected, too small ones become zero
+			if exponent >= 0 {
+				mantissa *= math.Pow10(exponent)
+			} else {
+				mantissa /= math.Pow10(-exponent)
+			}
+			return mantissa, !math.IsInf(mantissa, 0)
+		}
+	}
+
+	integer, fraction := s, ""
+	for idx := range s {
+		if s[idx] == '.' {
+			integer, fraction = s[:idx], s[idx+1:]
+			break
+		}
+	}
+
+	sign := 1.
+	if len(integer) > 0 && (integer[0] == '-' || integer[0] == '+') {
+		if integer[0] == '-' {
+			sign = -1
+		}
+		integer = integer[1:]
+	}
+	if len(integer)+len(fraction) == 0 || !IsNumeric(integer) || !IsNumeric(fraction) {
+		return 0, false
+	}
+
+	res := 0.
+	for _, char := range integer {
+		res = res*10 + float64(char-'0')
+	}
+	scale := 1.
+	for _, char := range fraction {
+		scale /= 10
+		res += float64(char-'0') * scale
+	}
+	return sign * res, true
+}
+
+// Replace returns the copy of s with all occurrences of old replaced by new
+func Replace(s, old, new string) string {
+	if old == "" {
+		return s
+	}
+	res := ""
+	for idx := 0; idx < len(s); idx++ {
+		if HasPrefix(old, s[idx:]) {
+			res += new
+			idx += len(old) - 1
+		} else {
+			res += string(s[idx])
+		}
+	}
+	return res
+}
+
+// Parallel runs the task for every index from 0 to count on the pool of workers,
+// errors are returned by the indices of tasks, non-positive number of workers means the number of CPUs
+func Parallel(count, workers int, task func(idx int) error) []error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	errs := make([]error, count)
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < min(workers, count); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indices {
+				errs[idx] = task(idx)
+			}
+		}()
+	}
+	for idx := 0; idx < count; idx++ {
+		indices <- idx
+	}
+	close(indices)
+	wg.Wait()
+
+	return errs
+}
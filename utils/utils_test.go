@@ -0,0 +1,38 @@
+package utils
+
+import "testing"
+
+func TestParseFloat(t *testing.T) {
+	type testData struct {
+		value string
+		want  float64
+		ok    bool
+	}
+
+	tests := []testData{
+		{value: "1.5", want: 1.5, ok: true},
+		{value: "-0.25", want: -0.25, ok: true},
+		{value: ".5", want: 0.5, ok: true},
+		{value: "9e1", want: 90, ok: true},
+		{value: "2.5E-2", want: 0.025, ok: true},
+		{value: "0e999", want: 0, ok: true},
+		// Too small values become zero
+		{value: "1e-400", want: 0, ok: true},
+		// Exponent is applied at once, so the huge one is rejected without looping over it
+		{value: "1e999999999", ok: false},
+		{value: "1e400", ok: false},
+		{value: "1e", ok: false},
+		{value: "e5", ok: false},
+		{value: "1.2.3", ok: false},
+		{value: "", ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, ok := ParseFloat(test.value)
+			if ok != test.ok || ok && got != test.want {
+				t.Errorf("ParseFloat(%q) = %v, %v, want %v, %v", test.value, got, ok, test.want, test.ok)
+			}
+		})
+	}
+}